@@ -1,30 +1,20 @@
 import { useEffect, useState } from "react";
 import { Message } from "@/types";
-import { v4 as uuidv4 } from "uuid";
 
 export function useChat() {
   const [messages, setMessages] = useState<Message[]>([]);
   const [isLoading, setIsLoading] = useState(false);
   const [isTyping, setIsTyping] = useState(false);
 
-  const getUserId = () => {
-    let userId = localStorage.getItem("userId");
-    if (!userId) {
-      userId = uuidv4();
-      localStorage.setItem("userId", userId);
-    }
-    return userId;
-  };
-
-  const userId = getUserId();
-
+  // The server identifies visitors by a session cookie it issues on the
+  // first request, so every call has to send credentials.
   const loadMessages = async () => {
-    const res = await fetch(
-      `${import.meta.env.VITE_API_URL}/chat?userId=${userId}`
-    );
+    const res = await fetch(`${import.meta.env.VITE_API_URL}/chat`, {
+      credentials: "include",
+    });
     const loaded = await res.json();
 
-    const loadedMessages: Message[] = loaded.map((msg: any) => ({
+    const loadedMessages: Message[] = loaded.messages.map((msg: any) => ({
       ...msg,
       disableAnimation: true, // prevent typing
     }));
@@ -32,6 +22,10 @@ export function useChat() {
   };
 
   useEffect(() => {
+    // Visitors used to be identified by a userId kept here. The server no
+    // longer accepts it, and history stored under it can't be claimed without
+    // letting anyone claim anyone's, so drop it.
+    localStorage.removeItem("userId");
     loadMessages();
   }, []);
 
@@ -48,8 +42,9 @@ export function useChat() {
     try {
       const res = await fetch(`${import.meta.env.VITE_API_URL}/chat`, {
         method: "POST",
+        credentials: "include",
         headers: { "Content-Type": "application/json" },
-        body: JSON.stringify({ userId: userId, content: msg }),
+        body: JSON.stringify({ content: msg }),
       });
 
       const data = await res.json();
@@ -1,4 +1,6 @@
 export type Message = {
+  id?: string;
+  timestamp?: string;
   role: "user" | "assistant";
   content: string;
   disableAnimation?: boolean;
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-ai/db"
+	"go-ai/jsonresume"
+	"go-ai/tenant"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// adminKinds maps the /admin/{kind} path segment to a constructor for the
+// model it edits.
+var adminKinds = map[string]func() db.GraphNode{
+	"projects":        func() db.GraphNode { return &db.Project{} },
+	"work-experience": func() db.GraphNode { return &db.WorkExperience{} },
+	"education":       func() db.GraphNode { return &db.Education{} },
+	"courses":         func() db.GraphNode { return &db.Course{} },
+	"hobbies":         func() db.GraphNode { return &db.Hobby{} },
+	"skills":          func() db.GraphNode { return &db.Skill{} },
+	"tags":            func() db.GraphNode { return &db.Tag{} },
+	"people":          func() db.GraphNode { return &db.Person{} },
+}
+
+// maxAdminBodyBytes caps the size of an admin request body.
+const maxAdminBodyBytes = 1 << 20
+
+// ─────────────────────────────────────────────────────────────────────────────
+// /admin — edits the resume graph of the request's tenant
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (s *server) adminRoutes(r chi.Router) {
+	r.Use(adminAuthMiddleware)
+
+	r.Get("/export", handleExport)
+	r.Post("/import/jsonresume", s.handleImportJSONResume)
+	r.Post("/relationships", s.handleCreateRelationship)
+	r.Delete("/relationships", s.handleDeleteRelationship)
+
+	r.Post("/{kind}", s.handleCreateNode)
+	r.Put("/{kind}/{key}", s.handleUpdateNode)
+	r.Delete("/{kind}/{key}", s.handleDeleteNode)
+}
+
+// Admin auth middleware: requires one of the tenant's admin keys as a bearer
+// token. Tenants without admin keys don't expose the admin API at all.
+func adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := tenant.FromContext(r.Context())
+		if len(t.AdminKeys) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !t.AllowsAdminKey(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleCreateNode(w http.ResponseWriter, r *http.Request) {
+	node, ok := decodeAdminNode(w, r)
+	if !ok {
+		return
+	}
+	if err := db.CreateNode(r.Context(), node); err != nil {
+		writeAdminError(w, "create", err)
+		return
+	}
+	s.refreshAfterWrite(r.Context())
+	writeJSON(w, http.StatusCreated, node)
+}
+
+func (s *server) handleUpdateNode(w http.ResponseWriter, r *http.Request) {
+	node, ok := decodeAdminNode(w, r)
+	if !ok {
+		return
+	}
+	if err := db.UpdateNode(r.Context(), chi.URLParam(r, "key"), node); err != nil {
+		writeAdminError(w, "update", err)
+		return
+	}
+	s.refreshAfterWrite(r.Context())
+	writeJSON(w, http.StatusOK, node)
+}
+
+func (s *server) handleDeleteNode(w http.ResponseWriter, r *http.Request) {
+	newNode, ok := adminKinds[chi.URLParam(r, "kind")]
+	if !ok {
+		http.Error(w, "Unknown kind", http.StatusNotFound)
+		return
+	}
+	ref := db.NodeRef{Label: newNode().Label(), Key: chi.URLParam(r, "key")}
+	if err := db.DeleteNode(r.Context(), ref); err != nil {
+		writeAdminError(w, "delete", err)
+		return
+	}
+	s.refreshAfterWrite(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) handleCreateRelationship(w http.ResponseWriter, r *http.Request) {
+	var rel db.Relationship
+	if !decodeAdminBody(w, r, &rel) {
+		return
+	}
+	if err := db.CreateRelationship(r.Context(), rel); err != nil {
+		writeAdminError(w, "link", err)
+		return
+	}
+	s.refreshAfterWrite(r.Context())
+	writeJSON(w, http.StatusCreated, rel)
+}
+
+func (s *server) handleDeleteRelationship(w http.ResponseWriter, r *http.Request) {
+	var rel db.Relationship
+	if !decodeAdminBody(w, r, &rel) {
+		return
+	}
+	if err := db.DeleteRelationship(r.Context(), rel); err != nil {
+		writeAdminError(w, "unlink", err)
+		return
+	}
+	s.refreshAfterWrite(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleImportJSONResume upserts the JSON Resume in the body and returns the
+// changeset; ?dryRun=true only plans it.
+func (s *server) handleImportJSONResume(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	cs, err := jsonresume.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxAdminBodyBytes), dryRun)
+	if err != nil {
+		writeAdminError(w, "import", err)
+		return
+	}
+	if !dryRun && !cs.Empty() {
+		s.refreshAfterWrite(r.Context())
+	}
+	writeJSON(w, http.StatusOK, cs)
+}
+
+// handleExport downloads the tenant's whole resume graph as an export document.
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	export, err := db.ExportGraph(r.Context())
+	if err != nil {
+		writeAdminError(w, "export", err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="resume-graph.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Internal: decoding, errors and cache refresh
+// ─────────────────────────────────────────────────────────────────────────────
+
+// decodeAdminNode decodes the body into the model named by the {kind} path segment.
+func decodeAdminNode(w http.ResponseWriter, r *http.Request) (db.GraphNode, bool) {
+	newNode, ok := adminKinds[chi.URLParam(r, "kind")]
+	if !ok {
+		http.Error(w, "Unknown kind", http.StatusNotFound)
+		return nil, false
+	}
+	node := newNode()
+	return node, decodeAdminBody(w, r, node)
+}
+
+// decodeAdminBody decodes a JSON body strictly, so misspelled fields are
+// rejected instead of silently dropped.
+func decodeAdminBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeAdminError maps db errors to status codes.
+func writeAdminError(w http.ResponseWriter, action string, err error) {
+	var invalid *db.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, db.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, db.ErrExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("[ERROR] Admin %s failed: %v", action, err)
+		http.Error(w, "Failed to "+action+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// refreshAfterWrite reloads what the planner caches about the graph, so the
+// next question sees the change.
+func (s *server) refreshAfterWrite(ctx context.Context) {
+	if err := db.LoadGraphSchemaOnce(ctx); err != nil {
+		log.Printf("⚠️ Failed to refresh graph schema: %v", err)
+	}
+	s.assistant.Planner.ResetVocabulary()
+}
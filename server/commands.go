@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"go-ai/config"
+	"go-ai/content"
+	"go-ai/db"
+	"go-ai/jsonresume"
+	"go-ai/openai"
+	"go-ai/tenant"
+	"log"
+	"os"
+)
+
+// migrateBatchSize is how many messages migrate-chats writes per transaction.
+const migrateBatchSize = 500
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CLI subcommands — `./app <command>` runs a one-off job instead of the server
+// ─────────────────────────────────────────────────────────────────────────────
+
+func runCommand(name string, args []string) {
+	switch name {
+	case "index":
+		runIndex(args)
+	case "alias":
+		runAlias(args)
+	case "import":
+		runImport(args)
+	case "sync":
+		runSync(args)
+	case "export":
+		runExport(args)
+	case "restore":
+		runRestore(args)
+	default:
+		log.Fatalf("❌ Unknown command %q (available: index, alias, import, sync, export, restore, migrate-chats)", name)
+	}
+}
+
+// runIndex embeds every changed resume node and ensures the vector indexes
+// exist, for one tenant (-tenant <id>) or all of them.
+func runIndex(args []string) {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "only index this tenant")
+	_ = fs.Parse(args)
+
+	for _, t := range tenant.All() {
+		if *tenantID != "" && t.ID != *tenantID {
+			continue
+		}
+		report, err := openai.IndexEmbeddings(tenant.NewContext(context.Background(), t))
+		if err != nil {
+			log.Fatalf("❌ Embedding index failed for tenant %s: %v", t.ID, err)
+		}
+		log.Printf("✅ Tenant %s: embedded %d of %d nodes (%d unchanged)", t.ID, report.Embedded, report.Total, report.Skipped)
+	}
+}
+
+// runAlias records another spelling for a Tag, Skill or Hobby:
+// `./app alias [-tenant <id>] Skill Go golang`.
+func runAlias(args []string) {
+	fs := flag.NewFlagSet("alias", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant to update (default: the first one)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 3 {
+		log.Fatalf("❌ Usage: alias [-tenant <id>] <Tag|Skill|Hobby> <name> <alias>")
+	}
+	label, name, alias := fs.Arg(0), fs.Arg(1), fs.Arg(2)
+	if err := db.AddAlias(tenantContext(*tenantID), label, name, alias); err != nil {
+		log.Fatalf("❌ Adding alias failed: %v", err)
+	}
+	log.Printf("✅ %s %q now also matches %q", label, name, alias)
+}
+
+// runImport upserts a JSON Resume file into the graph:
+// `./app import [-tenant <id>] [-dry-run] resume.json`. A dry run prints the
+// changeset without writing it.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant to import into (default: the first one)")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatalf("❌ Usage: import [-tenant <id>] [-dry-run] <resume.json>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ Failed to open resume: %v", err)
+	}
+	defer f.Close()
+
+	cs, err := jsonresume.Import(tenantContext(*tenantID), f, *dryRun)
+	if err != nil {
+		log.Fatalf("❌ Import failed: %v", err)
+	}
+	for _, entry := range cs.Skipped {
+		log.Printf("⚠️ Skipped %s", entry)
+	}
+	if *dryRun {
+		printJSON(cs)
+		log.Printf("🔍 Dry run: %s", cs.Summary())
+		return
+	}
+	log.Printf("✅ Imported %s: %s", fs.Arg(0), cs.Summary())
+}
+
+// runSync makes the graph mirror a content directory of Markdown files:
+// `./app sync [-tenant <id>] [-dry-run] [dir]`, defaulting to CONTENT_DIR.
+// The changeset is printed either way.
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant to sync (default: the first one)")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+	_ = fs.Parse(args)
+
+	dir := config.GetContentDir()
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	cs, err := content.Sync(tenantContext(*tenantID), dir, *dryRun)
+	if err != nil {
+		log.Fatalf("❌ Sync failed: %v", err)
+	}
+	printJSON(cs)
+	if *dryRun {
+		log.Printf("🔍 Dry run: %s", cs.Summary())
+		return
+	}
+	log.Printf("✅ Synced %s: %s", dir, cs.Summary())
+}
+
+// runExport writes the whole resume graph as a versioned JSON document:
+// `./app export [-tenant <id>] [-o backup.json]`, to stdout by default.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant to export (default: the first one)")
+	out := fs.String("o", "", "file to write (default: stdout)")
+	_ = fs.Parse(args)
+
+	export, err := db.ExportGraph(tenantContext(*tenantID))
+	if err != nil {
+		log.Fatalf("❌ Export failed: %v", err)
+	}
+	if *out == "" {
+		printJSON(export)
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("❌ Failed to create %s: %v", *out, err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", *out, err)
+	}
+	log.Printf("✅ Exported %d nodes and %d relationships to %s",
+		len(export.Graph.Nodes()), len(export.Graph.Relationships), *out)
+}
+
+// runRestore makes the graph mirror an export document:
+// `./app restore [-tenant <id>] [-dry-run] backup.json`.
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant to restore into (default: the first one)")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatalf("❌ Usage: restore [-tenant <id>] [-dry-run] <export.json>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ Failed to open export: %v", err)
+	}
+	defer f.Close()
+	export, err := db.ReadExport(f)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cs, err := db.RestoreGraph(tenantContext(*tenantID), export, *dryRun)
+	if err != nil {
+		log.Fatalf("❌ Restore failed: %v", err)
+	}
+	printJSON(cs)
+	if *dryRun {
+		log.Printf("🔍 Dry run: %s", cs.Summary())
+		return
+	}
+	log.Printf("✅ Restored %s: %s", fs.Arg(0), cs.Summary())
+}
+
+// runMigrateChats copies conversations, chat history and summaries from
+// MongoDB into the BoltDB chat store:
+// `./app migrate-chats [-tenant <id>] [-file chat.db]`. Everything keeps its
+// IDs and timestamps, so rerunning it is safe. main runs it without setup, as
+// it needs neither Neo4j nor the server's secrets. MONGO_COLLECTION must be
+// set: with CHAT_STORE=bolt it would otherwise default to "chats" and the
+// copy would silently read from the wrong collections.
+func runMigrateChats(args []string) {
+	config.LoadEnv()
+	fs := flag.NewFlagSet("migrate-chats", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "only migrate this tenant")
+	file := fs.String("file", config.GetChatStoreFile(), "BoltDB file to copy into")
+	_ = fs.Parse(args)
+
+	if err := config.MissingEnv("MONGO_URI", "MONGO_DB", "MONGO_COLLECTION"); err != nil {
+		log.Fatalf("❌ migrate-chats copies from MongoDB: %v", err)
+	}
+	if err := tenant.Load(); err != nil {
+		log.Fatalf("❌ Invalid tenant config: %v", err)
+	}
+	db.InitMongo()
+	store, err := db.OpenBoltChatStore(*file)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer store.Close()
+
+	for _, t := range tenant.All() {
+		if *tenantID != "" && t.ID != *tenantID {
+			continue
+		}
+		ctx := tenant.NewContext(context.Background(), t)
+
+		conversations := 0
+		err := db.EachConversation(ctx, func(conv db.Conversation) error {
+			conversations++
+			return store.ImportConversation(ctx, conv)
+		})
+		if err != nil {
+			log.Fatalf("❌ Migrating conversations failed for tenant %s: %v", t.ID, err)
+		}
+
+		var batch []db.ChatMessage
+		messages := 0
+		flush := func() error {
+			if err := store.ImportMessages(ctx, batch); err != nil {
+				return err
+			}
+			messages += len(batch)
+			batch = batch[:0]
+			return nil
+		}
+		err = db.EachMessage(ctx, func(msg db.ChatMessage) error {
+			batch = append(batch, msg)
+			if len(batch) < migrateBatchSize {
+				return nil
+			}
+			return flush()
+		})
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			log.Fatalf("❌ Migrating messages failed for tenant %s: %v", t.ID, err)
+		}
+
+		summaries := 0
+		err = db.EachSummary(ctx, func(summary db.ConversationSummary) error {
+			summaries++
+			return store.ImportSummary(ctx, summary)
+		})
+		if err != nil {
+			log.Fatalf("❌ Migrating summaries failed for tenant %s: %v", t.ID, err)
+		}
+		log.Printf("✅ Tenant %s: copied %d conversations, %d messages and %d summaries into %s", t.ID, conversations, messages, summaries, *file)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatalf("❌ Failed to encode output: %v", err)
+	}
+}
+
+// tenantContext scopes a command to the tenant with the given ID, or to the
+// first tenant when id is empty.
+func tenantContext(id string) context.Context {
+	ctx := context.Background()
+	if id == "" {
+		return ctx
+	}
+	for _, t := range tenant.All() {
+		if t.ID == id {
+			return tenant.NewContext(ctx, t)
+		}
+	}
+	log.Fatalf("❌ Unknown tenant %q", id)
+	return nil
+}
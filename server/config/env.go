@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -50,6 +55,15 @@ func GetFrontendOrigin() string {
 	return origin
 }
 
+// GetSessionSecret returns the key visitors' session tokens are signed with
+func GetSessionSecret() string {
+	secret := os.Getenv("SESSION_SECRET")
+	if secret == "" {
+		log.Fatal("❌ SESSION_SECRET not set in environment")
+	}
+	return secret
+}
+
 // GetServerPort returns the port the server should run on
 func GetServerPort() string {
 	port := os.Getenv("PORT")
@@ -76,8 +90,13 @@ func GetMongoDB() string {
 	return db
 }
 
+// GetMongoCollection names the chat collection; other chat stores use it as a
+// bucket name and don't require it
 func GetMongoCollection() string {
 	coll := os.Getenv("MONGO_COLLECTION")
+	if coll == "" && GetChatStore() != "mongo" {
+		return "chats"
+	}
 	if coll == "" {
 		log.Fatal("❌ MONGO_COLLECTION not set in environment")
 	}
@@ -119,3 +138,274 @@ func GetOllamaURI() string {
 	}
 	return uri
 }
+
+//
+// 🤖 LLM PROVIDERS
+//
+
+// getOrDefault returns the env value for key, or fallback when unset
+func getOrDefault(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
+
+// MissingEnv reports which of the given variables are unset, or nil when all are set
+func MissingEnv(keys ...string) error {
+	var missing []string
+	for _, key := range keys {
+		if os.Getenv(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s not set in environment", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetOptionalOpenAIEmbeddingURL returns the embedding URL without requiring it
+func GetOptionalOpenAIEmbeddingURL() string {
+	return os.Getenv("OPENAI_EMBEDDING_URL")
+}
+
+// GetPlannerProvider returns the provider kind used for graph planning (openai, ollama, fake)
+func GetPlannerProvider() string {
+	return getOrDefault("PLANNER_PROVIDER", "ollama")
+}
+
+// GetPlannerModel returns the model used for graph planning
+func GetPlannerModel() string {
+	return getOrDefault("PLANNER_MODEL", "llama3")
+}
+
+// GetPlannerStructuredOutput reports whether the planner is constrained to the plan JSON schema
+func GetPlannerStructuredOutput() bool {
+	return getBoolOrDefault("PLANNER_STRUCTURED_OUTPUT", true)
+}
+
+// GetPlannerFastPathEnabled reports whether common questions are planned by rules before the LLM
+func GetPlannerFastPathEnabled() bool {
+	return getBoolOrDefault("PLANNER_FASTPATH_ENABLED", true)
+}
+
+// GetPlannerFastPathMinConfidence returns the confidence a rule-based plan needs to skip the LLM
+func GetPlannerFastPathMinConfidence() float64 {
+	return getFloatOrDefault("PLANNER_FASTPATH_MIN_CONFIDENCE", 0.75)
+}
+
+// GetPlannerCacheTTL returns how long the planner reuses the graph schema and entity names it
+// loaded; graph changes made outside the server, e.g. by the import and sync commands, show up after it
+func GetPlannerCacheTTL() time.Duration {
+	return time.Duration(getIntOrDefault("PLANNER_CACHE_TTL_SECONDS", 300)) * time.Second
+}
+
+// GetResolverMinScore returns the fuzzy score a filter value needs to be mapped to a known name
+func GetResolverMinScore() float64 {
+	return getFloatOrDefault("RESOLVER_MIN_SCORE", 0.75)
+}
+
+// GetAnswerProvider returns the provider kind used for answering (openai, ollama, fake)
+func GetAnswerProvider() string {
+	return getOrDefault("ANSWER_PROVIDER", "openai")
+}
+
+// GetAnswerModel returns the model used for answering
+func GetAnswerModel() string {
+	return getOrDefault("ANSWER_MODEL", "gpt-3.5-turbo")
+}
+
+// GetEmbeddingProvider returns the provider kind used for embeddings (openai, ollama, fake)
+func GetEmbeddingProvider() string {
+	return getOrDefault("EMBEDDING_PROVIDER", "openai")
+}
+
+// GetEmbeddingModel returns the model used for embeddings
+func GetEmbeddingModel() string {
+	return getOrDefault("EMBEDDING_MODEL", "text-embedding-3-small")
+}
+
+//
+// 🧠 CONVERSATION MEMORY
+//
+
+// getIntOrDefault parses the env value for key as an int, or returns fallback
+func getIntOrDefault(key string, fallback int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		log.Printf("⚠️  %s=%q is not a number — using %d", key, val, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getBoolOrDefault parses the env value for key as a bool, or returns fallback
+func getBoolOrDefault(key string, fallback bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("⚠️  %s=%q is not a boolean — using %t", key, val, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getFloatOrDefault parses the env value for key as a float, or returns fallback
+func getFloatOrDefault(key string, fallback float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		log.Printf("⚠️  %s=%q is not a number — using %g", key, val, fallback)
+		return fallback
+	}
+	return f
+}
+
+// GetMemoryMaxTurns returns how many past exchanges (question + reply) are folded into prompts
+func GetMemoryMaxTurns() int {
+	return getIntOrDefault("MEMORY_MAX_TURNS", 6)
+}
+
+// GetMemoryTokenBudget returns the approximate token budget for past messages
+func GetMemoryTokenBudget() int {
+	return getIntOrDefault("MEMORY_TOKEN_BUDGET", 800)
+}
+
+// GetMemorySummaryEnabled reports whether older turns are summarised into Mongo
+func GetMemorySummaryEnabled() bool {
+	return getBoolOrDefault("MEMORY_SUMMARY_ENABLED", false)
+}
+
+// GetMongoSummaryCollection returns the collection holding rolling summaries
+func GetMongoSummaryCollection() string {
+	return getOrDefault("MONGO_SUMMARY_COLLECTION", GetMongoCollection()+"_summaries")
+}
+
+// GetMongoConversationCollection returns the collection holding conversation titles and counts
+func GetMongoConversationCollection() string {
+	return getOrDefault("MONGO_CONVERSATION_COLLECTION", GetMongoCollection()+"_conversations")
+}
+
+//
+// 🔎 RETRIEVAL
+//
+
+// GetSemanticRetrievalEnabled reports whether embedding search is used for context
+func GetSemanticRetrievalEnabled() bool {
+	return getBoolOrDefault("SEMANTIC_RETRIEVAL_ENABLED", false)
+}
+
+// GetRetrievalMaxResults caps how many ranked nodes make it into the answer context
+func GetRetrievalMaxResults() int {
+	return getIntOrDefault("RETRIEVAL_MAX_RESULTS", 8)
+}
+
+// GetContextTokenBudget returns the token budget for graph context (0 = derive from the answer model)
+func GetContextTokenBudget() int {
+	return getIntOrDefault("CONTEXT_TOKEN_BUDGET", 0)
+}
+
+// GetContextFieldTokenLimit returns how many tokens a single context field may use before it's truncated
+func GetContextFieldTokenLimit() int {
+	return getIntOrDefault("CONTEXT_FIELD_TOKEN_LIMIT", 120)
+}
+
+//
+// 🙋 PERSONA
+//
+
+// GetPersonaTemplateFile returns an optional text/template file for the persona system prompt
+func GetPersonaTemplateFile() string {
+	return os.Getenv("PERSONA_TEMPLATE_FILE")
+}
+
+// GetPersonaRulesFile returns an optional file of persona rules, one per line
+func GetPersonaRulesFile() string {
+	return os.Getenv("PERSONA_RULES_FILE")
+}
+
+//
+// 📝 CONTENT
+//
+
+// GetContentDir returns the directory of Markdown content the sync command mirrors into the graph
+func GetContentDir() string {
+	return getOrDefault("CONTENT_DIR", "content")
+}
+
+//
+// 🗄️ BACKENDS
+//
+
+// GetResumeFixture returns an optional export file served from memory instead of Neo4j and MongoDB
+func GetResumeFixture() string {
+	return os.Getenv("RESUME_FIXTURE")
+}
+
+// GetChatStore returns where chat history is kept: mongo, bolt or memory (memory by default with a fixture)
+func GetChatStore() string {
+	fallback := "mongo"
+	if GetResumeFixture() != "" {
+		fallback = "memory"
+	}
+	return getOrDefault("CHAT_STORE", fallback)
+}
+
+// GetChatStoreFile returns the BoltDB file used when CHAT_STORE is bolt
+func GetChatStoreFile() string {
+	return getOrDefault("CHAT_STORE_FILE", "chat.db")
+}
+
+// maxChatRetentionDays is the longest retention MongoDB's TTL indexes can
+// express, which take it in seconds as a 32-bit integer
+const maxChatRetentionDays = math.MaxInt32 / (24 * 60 * 60)
+
+// GetChatRetentionDays returns how many days chat history is kept; 0 keeps it forever
+func GetChatRetentionDays() int {
+	days := getIntOrDefault("CHAT_RETENTION_DAYS", 0)
+	if days < 0 || days > maxChatRetentionDays {
+		log.Fatalf("❌ CHAT_RETENTION_DAYS must be between 0 and %d, got %d", maxChatRetentionDays, days)
+	}
+	return days
+}
+
+//
+// 🏢 TENANTS
+//
+
+// GetTenantsFile returns an optional JSON file listing the tenants this server hosts
+func GetTenantsFile() string {
+	return os.Getenv("TENANTS_FILE")
+}
+
+// GetAllowedHosts returns the Host headers the default tenant answers on
+func GetAllowedHosts() []string {
+	return getListOrDefault("ALLOWED_HOSTS", "api.luxscious.dev")
+}
+
+// GetAdminAPIKeys returns the bearer tokens accepted by the default tenant's /admin API
+func GetAdminAPIKeys() []string {
+	return getListOrDefault("ADMIN_API_KEYS", "")
+}
+
+// getListOrDefault splits the comma-separated env value for key, or fallback when unset
+func getListOrDefault(key, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(getOrDefault(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,321 @@
+// Package content reads portfolio content kept as Markdown files with YAML
+// front matter and mirrors it into the resume graph.
+//
+// A content directory looks like:
+//
+//	person.md              the Person (optional; left alone when missing)
+//	projects/<id>.md       one Project per file
+//	work/<id>.md           one WorkExperience per file
+//	education/<id>.md      one Education per file
+//	courses/<id>.md        one Course per file
+//	hobbies/<slug>.md      one Hobby per file
+//
+// The Markdown body becomes the description (projects, courses, hobbies) or
+// summary (everything else), and an id missing from the front matter defaults
+// to the file name. Skills and tags have no files of their own: they exist for
+// as long as something lists them.
+package content
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-ai/db"
+	"go-ai/tenant"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// FRONT MATTER
+// ─────────────────────────────────────────────────────────────────────────────
+
+type personFile struct {
+	ID         string   `yaml:"id"`
+	Name       string   `yaml:"name"`
+	Pronouns   string   `yaml:"pronouns"`
+	Location   string   `yaml:"location"`
+	BirthMonth string   `yaml:"birthMonth"`
+	BirthYear  int      `yaml:"birthYear"`
+	Background []string `yaml:"background"`
+	VoiceTone  string   `yaml:"voiceTone"`
+}
+
+type projectFile struct {
+	ID             string   `yaml:"id"`
+	Name           string   `yaml:"name"`
+	Institution    string   `yaml:"institution"`
+	Image          string   `yaml:"image"`
+	Featured       bool     `yaml:"featured"`
+	StartDate      string   `yaml:"startDate"`
+	EndDate        string   `yaml:"endDate"`
+	Demo           string   `yaml:"demo"`
+	GitHub         string   `yaml:"github"`
+	Contributions  []string `yaml:"contributions"`
+	Skills         []string `yaml:"skills"`
+	Tags           []string `yaml:"tags"`
+	WorkExperience string   `yaml:"workExperience"`
+}
+
+type workFile struct {
+	ID        string   `yaml:"id"`
+	Company   string   `yaml:"company"`
+	Title     string   `yaml:"title"`
+	StartDate string   `yaml:"startDate"`
+	EndDate   string   `yaml:"endDate"`
+	Featured  bool     `yaml:"featured"`
+	Tags      []string `yaml:"tags"`
+}
+
+type educationFile struct {
+	ID          string   `yaml:"id"`
+	Institution string   `yaml:"institution"`
+	Field       string   `yaml:"field"`
+	Degree      string   `yaml:"degree"`
+	Level       string   `yaml:"level"`
+	StartDate   string   `yaml:"startDate"`
+	EndDate     string   `yaml:"endDate"`
+	Leadership  []string `yaml:"leadership"`
+	Tags        []string `yaml:"tags"`
+}
+
+type courseFile struct {
+	ID    string `yaml:"id"`
+	Title string `yaml:"title"`
+}
+
+type hobbyFile struct {
+	Name     string   `yaml:"name"`
+	Tags     []string `yaml:"tags"`
+	Inspired []string `yaml:"inspired"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// SYNC
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Sync makes the graph of the tenant ctx is scoped to mirror the content in
+// dir and returns the changeset. With dryRun the changeset is only planned.
+func Sync(ctx context.Context, dir string, dryRun bool) (db.Changeset, error) {
+	g, err := Load(dir, tenant.FromContext(ctx).PersonID)
+	if err != nil {
+		return db.Changeset{}, err
+	}
+	cs, err := db.PlanChangeset(ctx, g, db.PlanMirror)
+	if err != nil || dryRun {
+		return cs, err
+	}
+	return cs, db.ApplyChangeset(ctx, cs)
+}
+
+// Load reads a content directory into a resume graph. personID is the
+// default ID for person.md.
+func Load(dir, personID string) (db.ResumeGraph, error) {
+	var g db.ResumeGraph
+	skills, tags := newNames(), newNames()
+
+	var person personFile
+	body, err := readFile(filepath.Join(dir, "person.md"), &person)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return g, err
+	default:
+		g.Person = &db.Person{
+			ID:         db.FirstNonEmpty(person.ID, personID),
+			Name:       person.Name,
+			Summary:    body,
+			Pronouns:   person.Pronouns,
+			Location:   person.Location,
+			BirthMonth: person.BirthMonth,
+			BirthYear:  person.BirthYear,
+			Background: person.Background,
+			VoiceTone:  person.VoiceTone,
+		}
+	}
+
+	err = eachFile(dir, "projects", func(name string) any { return &projectFile{ID: name} }, func(v any, body string) {
+		p := v.(*projectFile)
+		g.Projects = append(g.Projects, db.Project{
+			ID:            p.ID,
+			Name:          p.Name,
+			Description:   body,
+			Institution:   p.Institution,
+			Image:         p.Image,
+			Featured:      p.Featured,
+			Contributions: p.Contributions,
+			StartDate:     p.StartDate,
+			EndDate:       p.EndDate,
+			Demo:          p.Demo,
+			GitHub:        p.GitHub,
+		})
+		from := db.NodeRef{Label: "Project", Key: p.ID}
+		g.Relationships = append(g.Relationships, links("USES", from, "Skill", skills.addAll(p.Skills))...)
+		g.Relationships = append(g.Relationships, links("HAS_TAG", from, "Tag", tags.addAll(p.Tags))...)
+		if p.WorkExperience != "" {
+			g.Relationships = append(g.Relationships, links("WORKED_ON", from, "WorkExperience", []string{p.WorkExperience})...)
+		}
+	})
+	if err != nil {
+		return g, err
+	}
+
+	err = eachFile(dir, "work", func(name string) any { return &workFile{ID: name} }, func(v any, body string) {
+		w := v.(*workFile)
+		g.WorkExperience = append(g.WorkExperience, db.WorkExperience{
+			ID:        w.ID,
+			Summary:   body,
+			Company:   w.Company,
+			Title:     w.Title,
+			StartDate: w.StartDate,
+			EndDate:   w.EndDate,
+			Featured:  w.Featured,
+		})
+		from := db.NodeRef{Label: "WorkExperience", Key: w.ID}
+		g.Relationships = append(g.Relationships, links("HAS_TAG", from, "Tag", tags.addAll(w.Tags))...)
+	})
+	if err != nil {
+		return g, err
+	}
+
+	err = eachFile(dir, "education", func(name string) any { return &educationFile{ID: name} }, func(v any, body string) {
+		e := v.(*educationFile)
+		g.Education = append(g.Education, db.Education{
+			ID:          e.ID,
+			Summary:     body,
+			Institution: e.Institution,
+			Field:       e.Field,
+			Degree:      e.Degree,
+			Level:       e.Level,
+			StartDate:   e.StartDate,
+			EndDate:     e.EndDate,
+			Leadership:  e.Leadership,
+		})
+		from := db.NodeRef{Label: "Education", Key: e.ID}
+		g.Relationships = append(g.Relationships, links("HAS_TAG", from, "Tag", tags.addAll(e.Tags))...)
+	})
+	if err != nil {
+		return g, err
+	}
+
+	err = eachFile(dir, "courses", func(name string) any { return &courseFile{ID: name} }, func(v any, body string) {
+		c := v.(*courseFile)
+		g.Courses = append(g.Courses, db.Course{ID: c.ID, Title: c.Title, Description: body})
+	})
+	if err != nil {
+		return g, err
+	}
+
+	err = eachFile(dir, "hobbies", func(string) any { return &hobbyFile{} }, func(v any, body string) {
+		h := v.(*hobbyFile)
+		g.Hobbies = append(g.Hobbies, db.Hobby{Name: h.Name, Description: body})
+		from := db.NodeRef{Label: "Hobby", Key: h.Name}
+		g.Relationships = append(g.Relationships, links("HAS_TAG", from, "Tag", tags.addAll(h.Tags))...)
+		g.Relationships = append(g.Relationships, links("INSPIRED", from, "Project", h.Inspired)...)
+	})
+	if err != nil {
+		return g, err
+	}
+
+	for _, name := range skills.list {
+		g.Skills = append(g.Skills, db.Skill{Name: name})
+	}
+	for _, name := range tags.list {
+		g.Tags = append(g.Tags, db.Tag{Name: name})
+	}
+	return g, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// eachFile decodes every .md file in dir/sub, in name order, into the value
+// newFrontMatter returns for its base name and hands it to add with the body.
+// A missing directory holds no files.
+func eachFile(dir, sub string, newFrontMatter func(name string) any, add func(v any, body string)) error {
+	paths, err := filepath.Glob(filepath.Join(dir, sub, "*.md"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		v := newFrontMatter(strings.TrimSuffix(filepath.Base(path), ".md"))
+		body, err := readFile(path, v)
+		if err != nil {
+			return err
+		}
+		add(v, body)
+	}
+	return nil
+}
+
+// readFile decodes a file's YAML front matter into v and returns the trimmed
+// Markdown body. Unknown front matter fields are an error, so typos surface.
+func readFile(path string, v any) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	if !ok {
+		return "", fmt.Errorf("%s: missing front matter", path)
+	}
+	front, body, ok := bytes.Cut(rest, []byte("\n---"))
+	if !ok {
+		return "", fmt.Errorf("%s: front matter is not closed with ---", path)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(front))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// links builds one relationship of the given type from a node to each name.
+func links(relType string, from db.NodeRef, toLabel string, keys []string) []db.Relationship {
+	var rels []db.Relationship
+	for _, key := range keys {
+		rels = append(rels, db.Relationship{Type: relType, From: from, To: db.NodeRef{Label: toLabel, Key: key}})
+	}
+	return rels
+}
+
+// names keeps the first spelling of each skill or tag, matched case-insensitively.
+type names struct {
+	byKey map[string]string
+	list  []string
+}
+
+func newNames() *names {
+	return &names{byKey: map[string]string{}}
+}
+
+// addAll records each name and returns the spellings kept for them.
+func (n *names) addAll(items []string) []string {
+	var kept []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key := strings.ToLower(item)
+		if _, ok := n.byKey[key]; !ok {
+			n.byKey[key] = item
+			n.list = append(n.list, item)
+		}
+		kept = append(kept, n.byKey[key])
+	}
+	return kept
+}
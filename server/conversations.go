@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-ai/db"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// defaultMessagePageSize is how many messages a page holds unless ?limit= says otherwise.
+	defaultMessagePageSize = 50
+	// maxMessagePageSize caps ?limit= on a page of messages.
+	maxMessagePageSize = 200
+	// maxTitleLength is how many characters of the first question a generated title keeps.
+	maxTitleLength = 60
+)
+
+// ConversationRequest creates or renames a conversation. UserID is optional;
+// when sent it must match the visitor's session.
+type ConversationRequest struct {
+	UserID string `json:"userId"`
+	Title  string `json:"title"`
+}
+
+// MessagePage is one page of a conversation's messages, oldest first. Total
+// counts all messages in the conversation.
+type MessagePage struct {
+	Messages []db.ChatMessage `json:"messages"`
+	Total    int              `json:"total"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// /conversations — a user's chat sessions
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (s *server) conversationRoutes(r chi.Router) {
+	r.Get("/", s.handleListConversations)
+	r.Post("/", s.handleCreateConversation)
+	r.Patch("/{id}", s.handleRenameConversation)
+	r.Delete("/{id}", s.handleDeleteConversation)
+	r.Get("/{id}/messages", s.handleGetConversationMessages)
+}
+
+// GET /conversations — lists the visitor's conversations, most recently active first
+func (s *server) handleListConversations(w http.ResponseWriter, r *http.Request) {
+	userId, ok := requestUser(w, r, r.URL.Query().Get("userId"))
+	if !ok {
+		return
+	}
+
+	conversations, err := s.assistant.Chats.ListConversations(r.Context(), userId)
+	if err != nil {
+		writeChatStoreError(w, "list conversations", err)
+		return
+	}
+	if conversations == nil {
+		conversations = []db.Conversation{}
+	}
+	writeJSON(w, http.StatusOK, conversations)
+}
+
+// POST /conversations — starts a conversation; without a title one is
+// generated from its first question
+func (s *server) handleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	var req ConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.UserID, ok = requestUser(w, r, req.UserID); !ok {
+		return
+	}
+
+	conv, err := s.assistant.Chats.CreateConversation(r.Context(), req.UserID, strings.TrimSpace(req.Title))
+	if err != nil {
+		writeChatStoreError(w, "create conversation", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, conv)
+}
+
+// PATCH /conversations/{id} — renames a conversation
+func (s *server) handleRenameConversation(w http.ResponseWriter, r *http.Request) {
+	var req ConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		http.Error(w, "Missing title", http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.UserID, ok = requestUser(w, r, req.UserID); !ok {
+		return
+	}
+
+	conv, err := s.assistant.Chats.RenameConversation(r.Context(), req.UserID, chi.URLParam(r, "id"), title)
+	if err != nil {
+		writeChatStoreError(w, "rename conversation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, conv)
+}
+
+// DELETE /conversations/{id} — deletes a conversation with its messages
+func (s *server) handleDeleteConversation(w http.ResponseWriter, r *http.Request) {
+	userId, ok := requestUser(w, r, r.URL.Query().Get("userId"))
+	if !ok {
+		return
+	}
+
+	if err := s.assistant.Chats.DeleteConversation(r.Context(), userId, chi.URLParam(r, "id")); err != nil {
+		writeChatStoreError(w, "delete conversation", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /conversations/{id}/messages?limit=...&offset=... — pages back through
+// a conversation: offset skips the newest messages and each page is returned
+// oldest first
+func (s *server) handleGetConversationMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userId, ok := requestUser(w, r, query.Get("userId"))
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(w, query.Get("limit"), "limit", defaultMessagePageSize)
+	if !ok {
+		return
+	}
+	offset, ok := queryInt(w, query.Get("offset"), "offset", 0)
+	if !ok {
+		return
+	}
+	limit = min(max(limit, 1), maxMessagePageSize)
+
+	id := chi.URLParam(r, "id")
+	if _, err := s.assistant.Chats.GetConversation(r.Context(), userId, id); err != nil {
+		writeChatStoreError(w, "load conversation", err)
+		return
+	}
+	messages, err := s.assistant.Chats.GetConversationMessages(r.Context(), userId, id, db.ConversationQuery{Offset: offset, Limit: limit})
+	if err != nil {
+		writeChatStoreError(w, "fetch messages", err)
+		return
+	}
+	total, err := s.assistant.Chats.CountConversationMessages(r.Context(), userId, id)
+	if err != nil {
+		writeChatStoreError(w, "count messages", err)
+		return
+	}
+
+	page := MessagePage{Messages: messages, Total: total}
+	if page.Messages == nil {
+		page.Messages = []db.ChatMessage{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Internal: query parsing, errors and titles
+// ─────────────────────────────────────────────────────────────────────────────
+
+// queryInt parses a non-negative query parameter, answering 400 if it isn't one.
+func queryInt(w http.ResponseWriter, value, name string, fallback int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		http.Error(w, "Invalid "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+// writeChatStoreError answers 404 for unknown conversations and 500 otherwise.
+func writeChatStoreError(w http.ResponseWriter, action string, err error) {
+	if errors.Is(err, db.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("[ERROR] Failed to %s: %v", action, err)
+	http.Error(w, "Failed to "+action, http.StatusInternalServerError)
+}
+
+// titleFromQuestion turns a conversation's first question into its title,
+// cut at a word boundary once it gets longer than maxTitleLength.
+func titleFromQuestion(question string) string {
+	title := strings.Join(strings.Fields(question), " ")
+	runes := []rune(title)
+	if len(runes) <= maxTitleLength {
+		return title
+	}
+	cut := string(runes[:maxTitleLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:!?-") + "…"
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-ai/db"
+)
+
+func TestConversations(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.visitor(t)
+
+	conv := decode[db.Conversation](t, v.do(http.MethodPost, "/conversations", ConversationRequest{}), http.StatusCreated)
+	if conv.ID == "" || conv.Title != "" {
+		t.Fatalf("created %+v, want an untitled conversation", conv)
+	}
+
+	// The first question titles the conversation
+	decode[ChatResponse](t, v.do(http.MethodPost, "/chat", ChatRequest{ConversationID: conv.ID, Message: "Tell me about Atlas"}), http.StatusOK)
+	list := decode[[]db.Conversation](t, v.do(http.MethodGet, "/conversations", nil), http.StatusOK)
+	if len(list) != 1 || list[0].ID != conv.ID || list[0].Title != "Tell me about Atlas" || list[0].MessageCount != 2 {
+		t.Fatalf("conversations = %+v", list)
+	}
+
+	messages := "/conversations/" + conv.ID + "/messages"
+	page := decode[MessagePage](t, v.do(http.MethodGet, messages+"?limit=1", nil), http.StatusOK)
+	if page.Total != 2 || len(page.Messages) != 1 || page.Messages[0].Role != "assistant" {
+		t.Errorf("newest page = %+v", page)
+	}
+	page = decode[MessagePage](t, v.do(http.MethodGet, messages+"?limit=1&offset=1", nil), http.StatusOK)
+	if page.Total != 2 || len(page.Messages) != 1 || page.Messages[0].Content != "Tell me about Atlas" {
+		t.Errorf("older page = %+v", page)
+	}
+	page = decode[MessagePage](t, v.do(http.MethodGet, messages+"?offset=5", nil), http.StatusOK)
+	if page.Total != 2 || page.Messages == nil || len(page.Messages) != 0 {
+		t.Errorf("page past the start = %+v, want no messages", page)
+	}
+
+	renamed := decode[db.Conversation](t, v.do(http.MethodPatch, "/conversations/"+conv.ID, ConversationRequest{Title: "  Atlas  "}), http.StatusOK)
+	if renamed.Title != "Atlas" {
+		t.Errorf("renamed title = %q", renamed.Title)
+	}
+	if rec := v.do(http.MethodPatch, "/conversations/"+conv.ID, ConversationRequest{Title: " "}); rec.Code != http.StatusBadRequest {
+		t.Errorf("blank title: status %d, want 400", rec.Code)
+	}
+
+	// Another visitor can't see or touch the conversation
+	other := ts.visitor(t)
+	if list := decode[[]db.Conversation](t, other.do(http.MethodGet, "/conversations", nil), http.StatusOK); len(list) != 0 {
+		t.Errorf("another visitor lists %+v", list)
+	}
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, messages},
+		{http.MethodPatch, "/conversations/" + conv.ID},
+		{http.MethodDelete, "/conversations/" + conv.ID},
+	} {
+		if rec := other.do(req.method, req.path, ConversationRequest{Title: "Mine"}); rec.Code != http.StatusNotFound {
+			t.Errorf("another visitor's %s %s: status %d, want 404", req.method, req.path, rec.Code)
+		}
+	}
+
+	if rec := v.do(http.MethodDelete, "/conversations/"+conv.ID, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d, want 204", rec.Code)
+	}
+	if rec := v.do(http.MethodGet, messages, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("messages of a deleted conversation: status %d, want 404", rec.Code)
+	}
+	if history := decode[db.HistoryPage](t, v.do(http.MethodGet, "/chat", nil), http.StatusOK); len(history.Messages) != 0 {
+		t.Errorf("deleting the conversation left %d messages", len(history.Messages))
+	}
+}
+
+func TestConversationMessagesPageBackwards(t *testing.T) {
+	v := newTestServer(t).visitor(t)
+	conv := decode[db.Conversation](t, v.do(http.MethodPost, "/conversations", ConversationRequest{Title: "Long"}), http.StatusCreated)
+	userID, ctx := v.userID()
+	for i := 1; i <= 7; i++ {
+		msg := db.ChatMessage{Role: "user", Content: fmt.Sprint(i), ConversationID: conv.ID}
+		if _, err := v.server.chats.StoreMessage(ctx, userID, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Messages outside the conversation don't count towards it
+	if _, err := v.server.chats.StoreMessage(ctx, userID, db.ChatMessage{Role: "user", Content: "elsewhere"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"?limit=3", "[5 6 7]"},
+		{"?limit=3&offset=3", "[2 3 4]"},
+		{"?limit=3&offset=6", "[1]"},
+		{"?limit=3&offset=7", "[]"},
+		{"?limit=0", "[7]"},
+	}
+	for _, tt := range tests {
+		page := decode[MessagePage](t, v.do(http.MethodGet, "/conversations/"+conv.ID+"/messages"+tt.query, nil), http.StatusOK)
+		var got []string
+		for _, msg := range page.Messages {
+			got = append(got, msg.Content)
+		}
+		if fmt.Sprint(got) != tt.want || page.Total != 7 {
+			t.Errorf("%s = %v of %d, want %s of 7", tt.query, got, page.Total, tt.want)
+		}
+	}
+}
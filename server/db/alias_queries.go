@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// AliasLabels are the node types whose names the entity resolver canonicalises.
+// Aliases are stored on the nodes themselves as an `aliases` string list.
+var AliasLabels = []string{"Tag", "Skill", "Hobby"}
+
+// EntityName is a node's canonical name together with its known aliases.
+type EntityName struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ListEntityNames returns every name and alias for one of the AliasLabels.
+func ListEntityNames(ctx context.Context, label string) ([]EntityName, error) {
+	if !slices.Contains(AliasLabels, label) {
+		return nil, fmt.Errorf("%s nodes don't carry aliases", label)
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		RETURN n.name AS name, COALESCE(n.aliases, []) AS aliases
+		ORDER BY name
+	`, label)
+
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []EntityName
+		for res.Next(ctx) {
+			record := res.Record()
+			names = append(names, EntityName{
+				Name:    asString(record, "name"),
+				Aliases: safeToStringSlice(record, "aliases"),
+			})
+		}
+		return names, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]EntityName), nil
+}
+
+// AddAlias records alias as another name for the node of the given label and
+// name (matched case-insensitively). Adding an existing alias is a no-op.
+func AddAlias(ctx context.Context, label, name, alias string) error {
+	if !slices.Contains(AliasLabels, label) {
+		return fmt.Errorf("%s nodes don't carry aliases", label)
+	}
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return fmt.Errorf("alias must not be empty")
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE toLower(n.name) = toLower($name)
+		SET n.aliases = CASE
+			WHEN $alias IN COALESCE(n.aliases, []) THEN n.aliases
+			ELSE COALESCE(n.aliases, []) + $alias
+		END
+		RETURN count(n) AS updated
+	`, label)
+
+	result, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, map[string]any{"name": name, "alias": alias})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		updated, _ := record.Get("updated")
+		return updated, nil
+	})
+	if err != nil {
+		return err
+	}
+	if result.(int64) == 0 {
+		return fmt.Errorf("no %s named %q", label, name)
+	}
+	return nil
+}
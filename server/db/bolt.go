@@ -0,0 +1,558 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"go-ai/tenant"
+	"slices"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ ChatStore = (*BoltChatStore)(nil)
+
+// BoltChatStore keeps chat history in a single BoltDB file, for deployments
+// that don't want to run MongoDB. Each tenant's chat collection is a bucket
+// holding one nested bucket per user, whose keys sort messages by timestamp;
+// conversations are kept the same way, by ID, under the tenant's conversation
+// collection, and summaries live in a bucket named after its summary
+// collection. Values are BSON, so documents look the same as in Mongo.
+type BoltChatStore struct {
+	db *bbolt.DB
+}
+
+// OpenBoltChatStore opens (or creates) the store at path. Only one process
+// can hold the file; opening fails after a second if another one does.
+func OpenBoltChatStore(path string) (*BoltChatStore, error) {
+	bdb, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chat store %s: %w", path, err)
+	}
+	return &BoltChatStore{db: bdb}, nil
+}
+
+// Close releases the file.
+func (s *BoltChatStore) Close() error {
+	return s.db.Close()
+}
+
+// StoreMessage saves a chat message and returns its hex ID. A message in a
+// conversation also counts towards it.
+func (s *BoltChatStore) StoreMessage(ctx context.Context, userID string, msg ChatMessage) (string, error) {
+	msg.UserID = userID
+	msg.Timestamp = time.Now()
+	if msg.ID.IsZero() {
+		msg.ID = primitive.NewObjectID()
+	}
+	t := tenant.FromContext(ctx)
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		if err := putMessage(tx, t.ChatCollection, msg); err != nil {
+			return err
+		}
+		if msg.ConversationID == "" {
+			return nil
+		}
+		conv, err := getConversation(tx, t.ConversationCollection, userID, msg.ConversationID)
+		if err != nil {
+			return err
+		}
+		conv.MessageCount++
+		conv.UpdatedAt = msg.Timestamp
+		return putConversation(tx, t.ConversationCollection, conv)
+	})
+	if err != nil {
+		return "", err
+	}
+	return msg.ID.Hex(), nil
+}
+
+// ImportMessages saves messages as they are, keeping their IDs and
+// timestamps. Importing a message again overwrites it, so a migration can be
+// rerun safely.
+func (s *BoltChatStore) ImportMessages(ctx context.Context, messages []ChatMessage) error {
+	collection := tenant.FromContext(ctx).ChatCollection
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for _, msg := range messages {
+			if msg.ID.IsZero() {
+				msg.ID = primitive.NewObjectID()
+			}
+			if err := putMessage(tx, collection, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetMessages retrieves all messages for a user, oldest first.
+func (s *BoltChatStore) GetMessages(ctx context.Context, userID string) ([]ChatMessage, error) {
+	return s.findMessages(ctx, userID, func(ChatMessage) bool { return true })
+}
+
+// GetConversationMessages retrieves the page q selects of one of a user's
+// conversations, oldest first, walking the user's bucket back from the newest
+// message; an empty conversationID selects the messages outside any.
+func (s *BoltChatStore) GetConversationMessages(ctx context.Context, userID, conversationID string, q ConversationQuery) ([]ChatMessage, error) {
+	var messages []ChatMessage
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		user := userBucket(tx, tenant.FromContext(ctx).ChatCollection, userID)
+		if user == nil {
+			return nil
+		}
+		skipped := 0
+		c := user.Cursor()
+		for k, doc := c.Last(); k != nil && (q.Limit <= 0 || len(messages) < q.Limit); k, doc = c.Prev() {
+			var msg ChatMessage
+			if err := bson.Unmarshal(doc, &msg); err != nil {
+				return fmt.Errorf("failed to decode message: %w", err)
+			}
+			if msg.ConversationID != conversationID {
+				continue
+			}
+			if skipped < q.Offset {
+				skipped++
+				continue
+			}
+			messages = append(messages, msg)
+		}
+		return nil
+	})
+	slices.Reverse(messages)
+	return messages, err
+}
+
+// CountConversationMessages counts the messages of one of a user's
+// conversations; an empty conversationID counts the messages outside any.
+func (s *BoltChatStore) CountConversationMessages(ctx context.Context, userID, conversationID string) (int, error) {
+	messages, err := s.findMessages(ctx, userID, func(msg ChatMessage) bool { return msg.ConversationID == conversationID })
+	return len(messages), err
+}
+
+// GetHistory returns the window of a user's messages selected by q, walking
+// the user's bucket from the cursor the window is anchored to.
+func (s *BoltChatStore) GetHistory(ctx context.Context, userID string, q HistoryQuery) (HistoryPage, error) {
+	var messages []ChatMessage
+	forward := q.After != ""
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		user := userBucket(tx, tenant.FromContext(ctx).ChatCollection, userID)
+		var before, after []byte
+		for _, cursor := range []struct {
+			id  string
+			key *[]byte
+		}{{q.Before, &before}, {q.After, &after}} {
+			if cursor.id == "" {
+				continue
+			}
+			key := findMessageKey(user, cursor.id)
+			if key == nil {
+				return messageNotFound(cursor.id)
+			}
+			*cursor.key = key
+		}
+		if user == nil {
+			return nil
+		}
+
+		c := user.Cursor()
+		var k, doc []byte
+		switch {
+		case forward:
+			if k, doc = c.Seek(after); bytes.Equal(k, after) {
+				k, doc = c.Next()
+			}
+		case before != nil:
+			c.Seek(before)
+			k, doc = c.Prev()
+		default:
+			k, doc = c.Last()
+		}
+
+		// Read one past the limit to learn whether more follow
+		for k != nil && len(messages) <= q.Limit {
+			if forward && before != nil && bytes.Compare(k, before) >= 0 {
+				break
+			}
+			var msg ChatMessage
+			if err := bson.Unmarshal(doc, &msg); err != nil {
+				return fmt.Errorf("failed to decode message: %w", err)
+			}
+			messages = append(messages, msg)
+			if forward {
+				k, doc = c.Next()
+			} else {
+				k, doc = c.Prev()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	return newHistoryPage(messages, q.Limit, forward), nil
+}
+
+// GetSummary returns the stored summary for a user's conversation, or nil if
+// none exists yet.
+func (s *BoltChatStore) GetSummary(ctx context.Context, userID, conversationID string) (*ConversationSummary, error) {
+	var summary *ConversationSummary
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		summaries := tx.Bucket([]byte(tenant.FromContext(ctx).SummaryCollection))
+		if summaries == nil {
+			return nil
+		}
+		doc := summaries.Get(summaryKey(userID, conversationID))
+		if doc == nil {
+			return nil
+		}
+		summary = &ConversationSummary{}
+		return bson.Unmarshal(doc, summary)
+	})
+	return summary, err
+}
+
+// StoreSummary upserts the rolling summary for a user's conversation.
+func (s *BoltChatStore) StoreSummary(ctx context.Context, summary ConversationSummary) error {
+	summary.UpdatedAt = time.Now()
+	return s.ImportSummary(ctx, summary)
+}
+
+// ImportSummary upserts a summary as it is, keeping its UpdatedAt.
+func (s *BoltChatStore) ImportSummary(ctx context.Context, summary ConversationSummary) error {
+	doc, err := bson.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		summaries, err := tx.CreateBucketIfNotExists([]byte(tenant.FromContext(ctx).SummaryCollection))
+		if err != nil {
+			return err
+		}
+		return summaries.Put(summaryKey(summary.UserID, summary.ConversationID), doc)
+	})
+}
+
+// CreateConversation starts a conversation for a user.
+func (s *BoltChatStore) CreateConversation(ctx context.Context, userID, title string) (Conversation, error) {
+	conv := newConversation(userID, title)
+	return conv, s.ImportConversation(ctx, conv)
+}
+
+// ImportConversation saves a conversation as it is, replacing one with the same ID.
+func (s *BoltChatStore) ImportConversation(ctx context.Context, conv Conversation) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putConversation(tx, tenant.FromContext(ctx).ConversationCollection, conv)
+	})
+}
+
+// ListConversations returns a user's conversations, most recently active first.
+func (s *BoltChatStore) ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	var conversations []Conversation
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		user := userBucket(tx, tenant.FromContext(ctx).ConversationCollection, userID)
+		if user == nil {
+			return nil
+		}
+		return user.ForEach(func(_, doc []byte) error {
+			var conv Conversation
+			if err := bson.Unmarshal(doc, &conv); err != nil {
+				return fmt.Errorf("failed to decode conversation: %w", err)
+			}
+			conversations = append(conversations, conv)
+			return nil
+		})
+	})
+	slices.SortStableFunc(conversations, func(a, b Conversation) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return conversations, err
+}
+
+// GetConversation returns one of a user's conversations, or ErrNotFound.
+func (s *BoltChatStore) GetConversation(ctx context.Context, userID, conversationID string) (Conversation, error) {
+	var conv Conversation
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		conv, err = getConversation(tx, tenant.FromContext(ctx).ConversationCollection, userID, conversationID)
+		return err
+	})
+	return conv, err
+}
+
+// RenameConversation sets a conversation's title and returns the result.
+func (s *BoltChatStore) RenameConversation(ctx context.Context, userID, conversationID, title string) (Conversation, error) {
+	var conv Conversation
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		collection := tenant.FromContext(ctx).ConversationCollection
+		var err error
+		if conv, err = getConversation(tx, collection, userID, conversationID); err != nil {
+			return err
+		}
+		conv.Title = title
+		return putConversation(tx, collection, conv)
+	})
+	return conv, err
+}
+
+// DeleteConversation removes a conversation with its messages and summary.
+func (s *BoltChatStore) DeleteConversation(ctx context.Context, userID, conversationID string) error {
+	t := tenant.FromContext(ctx)
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		conversations := userBucket(tx, t.ConversationCollection, userID)
+		if conversations == nil || conversations.Get([]byte(conversationID)) == nil {
+			return conversationNotFound(conversationID)
+		}
+		if err := conversations.Delete([]byte(conversationID)); err != nil {
+			return err
+		}
+
+		if messages := userBucket(tx, t.ChatCollection, userID); messages != nil {
+			var stale [][]byte
+			err := messages.ForEach(func(key, doc []byte) error {
+				var msg ChatMessage
+				if err := bson.Unmarshal(doc, &msg); err != nil {
+					return fmt.Errorf("failed to decode message: %w", err)
+				}
+				if msg.ConversationID == conversationID {
+					stale = append(stale, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, key := range stale {
+				if err := messages.Delete(key); err != nil {
+					return err
+				}
+			}
+		}
+
+		if summaries := tx.Bucket([]byte(t.SummaryCollection)); summaries != nil {
+			return summaries.Delete(summaryKey(userID, conversationID))
+		}
+		return nil
+	})
+}
+
+// DeleteUserHistory removes every message, conversation and summary of a user.
+func (s *BoltChatStore) DeleteUserHistory(ctx context.Context, userID string) error {
+	t := tenant.FromContext(ctx)
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for _, collection := range []string{t.ChatCollection, t.ConversationCollection} {
+			if userBucket(tx, collection, userID) == nil {
+				continue
+			}
+			if err := tx.Bucket([]byte(collection)).DeleteBucket([]byte(userID)); err != nil {
+				return err
+			}
+		}
+		return deleteSummaries(tx, t.SummaryCollection, func(summary ConversationSummary) bool {
+			return summary.UserID == userID
+		})
+	})
+}
+
+// Prune removes the messages of the tenant ctx is scoped to that are older
+// than before, and the conversations and summaries not updated since, and
+// returns how many messages it removed. It stands in for the TTL indexes
+// MongoDB expires history with.
+func (s *BoltChatStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	t := tenant.FromContext(ctx)
+	pruned := 0
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		// Message keys start with their timestamp, so each user's expired
+		// messages are the ones sorting before the cutoff
+		cutoff := binary.BigEndian.AppendUint64(nil, uint64(before.UnixNano()))
+		if chats := tx.Bucket([]byte(t.ChatCollection)); chats != nil {
+			err := chats.ForEachBucket(func(userID []byte) error {
+				c := chats.Bucket(userID).Cursor()
+				for k, _ := c.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, _ = c.First() {
+					if err := c.Delete(); err != nil {
+						return err
+					}
+					pruned++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if conversations := tx.Bucket([]byte(t.ConversationCollection)); conversations != nil {
+			err := conversations.ForEachBucket(func(userID []byte) error {
+				user := conversations.Bucket(userID)
+				var stale [][]byte
+				err := user.ForEach(func(id, doc []byte) error {
+					var conv Conversation
+					if err := bson.Unmarshal(doc, &conv); err != nil {
+						return fmt.Errorf("failed to decode conversation: %w", err)
+					}
+					if conv.UpdatedAt.Before(before) {
+						stale = append(stale, id)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				for _, id := range stale {
+					if err := user.Delete(id); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return deleteSummaries(tx, t.SummaryCollection, func(summary ConversationSummary) bool {
+			return summary.UpdatedAt.Before(before)
+		})
+	})
+	return pruned, err
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// messageKey orders messages by timestamp, then ID: the big-endian Unix
+// nanoseconds followed by the 12 ObjectID bytes.
+func messageKey(msg ChatMessage) []byte {
+	key := binary.BigEndian.AppendUint64(nil, uint64(msg.Timestamp.UnixNano()))
+	return append(key, msg.ID[:]...)
+}
+
+// findMessages returns a user's messages that keep matches, oldest first.
+func (s *BoltChatStore) findMessages(ctx context.Context, userID string, keep func(ChatMessage) bool) ([]ChatMessage, error) {
+	var messages []ChatMessage
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		user := userBucket(tx, tenant.FromContext(ctx).ChatCollection, userID)
+		if user == nil {
+			return nil
+		}
+		return user.ForEach(func(_, doc []byte) error {
+			var msg ChatMessage
+			if err := bson.Unmarshal(doc, &msg); err != nil {
+				return fmt.Errorf("failed to decode message: %w", err)
+			}
+			if keep(msg) {
+				messages = append(messages, msg)
+			}
+			return nil
+		})
+	})
+	return messages, err
+}
+
+// findMessageKey returns the key of the message with the given hex ID in a
+// user's bucket, or nil. Keys end in the message ID, so no value is decoded.
+func findMessageKey(user *bbolt.Bucket, messageID string) []byte {
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil || user == nil {
+		return nil
+	}
+	c := user.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if bytes.HasSuffix(k, id[:]) {
+			return slices.Clone(k)
+		}
+	}
+	return nil
+}
+
+func putMessage(tx *bbolt.Tx, collection string, msg ChatMessage) error {
+	user, err := createUserBucket(tx, collection, msg.UserID)
+	if err != nil {
+		return err
+	}
+	doc, err := bson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return user.Put(messageKey(msg), doc)
+}
+
+func getConversation(tx *bbolt.Tx, collection, userID, conversationID string) (Conversation, error) {
+	var conv Conversation
+	user := userBucket(tx, collection, userID)
+	if user == nil {
+		return conv, conversationNotFound(conversationID)
+	}
+	doc := user.Get([]byte(conversationID))
+	if doc == nil {
+		return conv, conversationNotFound(conversationID)
+	}
+	return conv, bson.Unmarshal(doc, &conv)
+}
+
+func putConversation(tx *bbolt.Tx, collection string, conv Conversation) error {
+	user, err := createUserBucket(tx, collection, conv.UserID)
+	if err != nil {
+		return err
+	}
+	doc, err := bson.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	return user.Put([]byte(conv.ID), doc)
+}
+
+// deleteSummaries removes the summaries in collection that match.
+func deleteSummaries(tx *bbolt.Tx, collection string, match func(ConversationSummary) bool) error {
+	summaries := tx.Bucket([]byte(collection))
+	if summaries == nil {
+		return nil
+	}
+	var stale [][]byte
+	err := summaries.ForEach(func(key, doc []byte) error {
+		var summary ConversationSummary
+		if err := bson.Unmarshal(doc, &summary); err != nil {
+			return fmt.Errorf("failed to decode summary: %w", err)
+		}
+		if match(summary) {
+			stale = append(stale, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := summaries.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summaryKey keeps a user's summary outside any conversation under their
+// bare user ID.
+func summaryKey(userID, conversationID string) []byte {
+	if conversationID == "" {
+		return []byte(userID)
+	}
+	return []byte(userID + "/" + conversationID)
+}
+
+func createUserBucket(tx *bbolt.Tx, collection, userID string) (*bbolt.Bucket, error) {
+	parent, err := tx.CreateBucketIfNotExists([]byte(collection))
+	if err != nil {
+		return nil, err
+	}
+	return parent.CreateBucketIfNotExists([]byte(userID))
+}
+
+func userBucket(tx *bbolt.Tx, collection, userID string) *bbolt.Bucket {
+	chats := tx.Bucket([]byte(collection))
+	if chats == nil {
+		return nil
+	}
+	return chats.Bucket([]byte(userID))
+}
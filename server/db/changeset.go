@@ -0,0 +1,387 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Change operations.
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// PlanMode decides how a ResumeGraph is reconciled with the stored graph.
+type PlanMode int
+
+const (
+	// PlanUpsert creates and updates the nodes in the graph and leaves
+	// everything else alone. Blank fields keep their stored values, so a
+	// partial source never erases what another one filled in.
+	PlanUpsert PlanMode = iota
+	// PlanMirror makes the stored graph match exactly: fields are overwritten
+	// even when blank, and nodes and relationships missing from the graph are
+	// deleted, apart from Person nodes. It sees the whole database, which
+	// tenant.Load keeps to one tenant each.
+	PlanMirror
+	// PlanRestore mirrors like PlanMirror but only requires nodes to have a
+	// key, so a backup restores as it was taken even where it predates the
+	// model validation.
+	PlanRestore
+)
+
+// FieldChange is one property whose stored value differs from the new one.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// NodeChange is a node that will be created, updated or deleted.
+type NodeChange struct {
+	Op     string        `json:"op"`
+	Label  string        `json:"label"`
+	Key    string        `json:"key"`
+	Fields []FieldChange `json:"fields,omitempty"`
+
+	props map[string]any
+}
+
+// RelationshipChange is a relationship that will be created or deleted.
+type RelationshipChange struct {
+	Op string `json:"op"`
+	Relationship
+}
+
+// Changeset is the difference between a ResumeGraph and the stored graph.
+// Skipped lists input entries left out because the graph can't hold them,
+// e.g. sparse JSON Resume entries.
+type Changeset struct {
+	Nodes         []NodeChange         `json:"nodes"`
+	Relationships []RelationshipChange `json:"relationships"`
+	Unchanged     int                  `json:"unchanged"`
+	Skipped       []string             `json:"skipped,omitempty"`
+}
+
+// Empty reports whether applying the changeset would change nothing.
+func (c Changeset) Empty() bool {
+	return len(c.Nodes) == 0 && len(c.Relationships) == 0
+}
+
+// Summary counts the changes by operation, e.g. for a log line.
+func (c Changeset) Summary() string {
+	counts := map[string]int{}
+	for _, n := range c.Nodes {
+		counts["node "+n.Op]++
+	}
+	for _, r := range c.Relationships {
+		counts["relationship "+r.Op]++
+	}
+	summary := fmt.Sprintf("%d nodes created, %d updated, %d deleted, %d unchanged; %d relationships created, %d deleted",
+		counts["node "+ChangeCreate], counts["node "+ChangeUpdate], counts["node "+ChangeDelete], c.Unchanged,
+		counts["relationship "+ChangeCreate], counts["relationship "+ChangeDelete])
+	if len(c.Skipped) > 0 {
+		summary += fmt.Sprintf("; %d entries skipped", len(c.Skipped))
+	}
+	return summary
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PLANNING
+// ─────────────────────────────────────────────────────────────────────────────
+
+// PlanChangeset works out what reconciling g with the stored graph in the
+// given mode would change. Nodes are matched by key.
+func PlanChangeset(ctx context.Context, g ResumeGraph, mode PlanMode) (Changeset, error) {
+	var cs Changeset
+
+	stored := map[string]map[string]map[string]any{}
+	storedNodes := func(label string) (map[string]map[string]any, error) {
+		if props, ok := stored[label]; ok {
+			return props, nil
+		}
+		props, err := loadNodeProps(ctx, label)
+		stored[label] = props
+		return props, err
+	}
+
+	mirror := mode == PlanMirror || mode == PlanRestore
+	seen := map[NodeRef]bool{}
+	for _, n := range g.Nodes() {
+		if mode == PlanRestore && strings.TrimSpace(n.Key()) == "" {
+			return Changeset{}, fmt.Errorf("%s without a %s", n.Label(), keyProperties[n.Label()])
+		} else if mode != PlanRestore {
+			if err := n.Validate(); err != nil {
+				return Changeset{}, fmt.Errorf("%s %q: %w", n.Label(), n.Key(), err)
+			}
+		}
+		ref := NodeRef{Label: n.Label(), Key: n.Key()}
+		if seen[ref] {
+			return Changeset{}, fmt.Errorf("%s %q appears twice", ref.Label, ref.Key)
+		}
+		seen[ref] = true
+
+		nodes, err := storedNodes(ref.Label)
+		if err != nil {
+			return Changeset{}, err
+		}
+		props := n.Properties()
+		current, exists := nodes[ref.Key]
+		if !exists {
+			cs.Nodes = append(cs.Nodes, NodeChange{Op: ChangeCreate, Label: ref.Label, Key: ref.Key, props: props})
+			continue
+		}
+		changed := map[string]any{}
+		var fields []FieldChange
+		for _, field := range slices.Sorted(maps.Keys(props)) {
+			value := props[field]
+			if (mode == PlanUpsert && isBlank(value)) || sameValue(current[field], value) {
+				continue
+			}
+			changed[field] = value
+			fields = append(fields, FieldChange{Field: field, From: current[field], To: value})
+		}
+		if len(fields) == 0 {
+			cs.Unchanged++
+			continue
+		}
+		cs.Nodes = append(cs.Nodes, NodeChange{Op: ChangeUpdate, Label: ref.Label, Key: ref.Key, Fields: fields, props: changed})
+	}
+
+	deleted := map[NodeRef]bool{}
+	if mirror {
+		for _, label := range slices.Sorted(maps.Keys(keyProperties)) {
+			if label == "Person" {
+				continue
+			}
+			nodes, err := storedNodes(label)
+			if err != nil {
+				return Changeset{}, err
+			}
+			for _, key := range slices.Sorted(maps.Keys(nodes)) {
+				if ref := (NodeRef{Label: label, Key: key}); !seen[ref] {
+					deleted[ref] = true
+					cs.Nodes = append(cs.Nodes, NodeChange{Op: ChangeDelete, Label: label, Key: key})
+				}
+			}
+		}
+	}
+
+	links, err := loadRelationships(ctx)
+	if err != nil {
+		return Changeset{}, err
+	}
+	wanted := map[Relationship]bool{}
+	for _, rel := range g.Relationships {
+		wanted[rel] = true
+		if err := rel.Validate(); err != nil {
+			return Changeset{}, err
+		}
+		for _, end := range []NodeRef{rel.From, rel.To} {
+			nodes, err := storedNodes(end.Label)
+			if err != nil {
+				return Changeset{}, err
+			}
+			// A mirrored graph must contain both ends itself
+			if _, ok := nodes[end.Key]; !seen[end] && (!ok || mirror) {
+				return Changeset{}, fmt.Errorf("%s relationship points at unknown %s %q", rel.Type, end.Label, end.Key)
+			}
+		}
+		if links[rel] {
+			continue
+		}
+		links[rel] = true
+		cs.Relationships = append(cs.Relationships, RelationshipChange{Op: ChangeCreate, Relationship: rel})
+	}
+
+	if mirror {
+		var stale []Relationship
+		for rel := range links {
+			// Relationships of deleted nodes go with them
+			if !wanted[rel] && !deleted[rel.From] && !deleted[rel.To] {
+				stale = append(stale, rel)
+			}
+		}
+		slices.SortFunc(stale, compareRelationships)
+		for _, rel := range stale {
+			cs.Relationships = append(cs.Relationships, RelationshipChange{Op: ChangeDelete, Relationship: rel})
+		}
+	}
+	return cs, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// APPLYING
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ApplyChangeset writes a planned changeset in a single transaction, nodes
+// before relationships, so a failure leaves the graph untouched.
+func ApplyChangeset(ctx context.Context, cs Changeset) error {
+	if cs.Empty() {
+		return nil
+	}
+	_, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for _, n := range cs.Nodes {
+			query, params := nodeChangeQuery(n)
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, fmt.Errorf("failed to %s %s %q: %w", n.Op, n.Label, n.Key, err)
+			}
+		}
+		for _, r := range cs.Relationships {
+			query, params := relationshipChangeQuery(r)
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, fmt.Errorf("failed to %s %s relationship: %w", r.Op, r.Type, err)
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+func nodeChangeQuery(n NodeChange) (string, map[string]any) {
+	keyProp := keyProperties[n.Label]
+	params := map[string]any{"key": n.Key, "props": n.props}
+	switch n.Op {
+	case ChangeCreate:
+		return fmt.Sprintf(`CREATE (n:%s) SET n = $props`, n.Label), params
+	case ChangeDelete:
+		return fmt.Sprintf(`MATCH (n:%s {%s: $key}) DETACH DELETE n`, n.Label, keyProp), params
+	default:
+		return fmt.Sprintf(`MATCH (n:%s {%s: $key}) SET n += $props`, n.Label, keyProp), params
+	}
+}
+
+func relationshipChangeQuery(r RelationshipChange) (string, map[string]any) {
+	params := map[string]any{"from": r.From.Key, "to": r.To.Key}
+	from := fmt.Sprintf("(a:%s {%s: $from})", r.From.Label, keyProperties[r.From.Label])
+	to := fmt.Sprintf("(b:%s {%s: $to})", r.To.Label, keyProperties[r.To.Label])
+	if r.Op == ChangeDelete {
+		return fmt.Sprintf(`MATCH %s-[r:%s]->%s DELETE r`, from, r.Type, to), params
+	}
+	return fmt.Sprintf(`MATCH %s, %s MERGE (a)-[:%s]->(b)`, from, to, r.Type), params
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// loadNodeProps returns the stored properties of every node with the label, by key.
+func loadNodeProps(ctx context.Context, label string) (map[string]map[string]any, error) {
+	keyProp, ok := keyProperties[label]
+	if !ok {
+		return nil, fmt.Errorf("unknown node label %q", label)
+	}
+	query := fmt.Sprintf(`MATCH (n:%s) RETURN n`, label)
+
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		nodes := map[string]map[string]any{}
+		for res.Next(ctx) {
+			val, _ := res.Record().Get("n")
+			props := val.(neo4j.Node).Props
+			nodes[toString(props[keyProp])] = props
+		}
+		return nodes, res.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s nodes: %w", label, err)
+	}
+	return result.(map[string]map[string]any), nil
+}
+
+// loadRelationships returns every stored relationship of the types the
+// resume graph uses.
+func loadRelationships(ctx context.Context) (map[Relationship]bool, error) {
+	types := slices.Sorted(maps.Keys(relationshipRules))
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (a)-[r]->(b)
+			WHERE type(r) IN $types
+			RETURN labels(a)[0] AS fromLabel, a.id AS fromId, a.name AS fromName,
+			       type(r) AS type,
+			       labels(b)[0] AS toLabel, b.id AS toId, b.name AS toName
+		`, map[string]any{"types": types})
+		if err != nil {
+			return nil, err
+		}
+		links := map[Relationship]bool{}
+		for res.Next(ctx) {
+			record := res.Record()
+			links[Relationship{
+				Type: asString(record, "type"),
+				From: refFromRecord(record, "from"),
+				To:   refFromRecord(record, "to"),
+			}] = true
+		}
+		return links, res.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+	return result.(map[Relationship]bool), nil
+}
+
+// refFromRecord builds a NodeRef from the <prefix>Label/Id/Name columns.
+func refFromRecord(record *neo4j.Record, prefix string) NodeRef {
+	label := asString(record, prefix+"Label")
+	if keyProperties[label] == "id" {
+		return NodeRef{Label: label, Key: asString(record, prefix+"Id")}
+	}
+	return NodeRef{Label: label, Key: asString(record, prefix+"Name")}
+}
+
+func compareRelationships(a, b Relationship) int {
+	return strings.Compare(
+		a.Type+"\x00"+a.From.Label+"\x00"+a.From.Key+"\x00"+a.To.Label+"\x00"+a.To.Key,
+		b.Type+"\x00"+b.From.Label+"\x00"+b.From.Key+"\x00"+b.To.Label+"\x00"+b.To.Key,
+	)
+}
+
+// isBlank reports whether a property value is empty and so left unset by an upsert.
+func isBlank(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case bool:
+		return !val
+	case []string:
+		return len(val) == 0
+	case int:
+		return val == 0
+	default:
+		return false
+	}
+}
+
+// sameValue compares a stored property with a model value, allowing for the
+// driver returning int64 and []any where the models use int and []string.
+func sameValue(stored, value any) bool {
+	return reflect.DeepEqual(normalizeValue(stored), normalizeValue(value))
+}
+
+func normalizeValue(v any) any {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case []string:
+		items := make([]any, len(val))
+		for i, s := range val {
+			items[i] = s
+		}
+		return items
+	default:
+		return v
+	}
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-ai/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Conversation is one chat session of a user. Its title is generated from
+// the first question unless the user names it.
+type Conversation struct {
+	ID           string    `bson:"_id" json:"id"`
+	UserID       string    `bson:"user_id" json:"-"`
+	Title        string    `bson:"title" json:"title"`
+	CreatedAt    time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updatedAt"`
+	MessageCount int       `bson:"message_count" json:"messageCount"`
+}
+
+// newConversation starts an empty conversation with a fresh ID.
+func newConversation(userID, title string) Conversation {
+	now := time.Now()
+	return Conversation{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    userID,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// conversationCollection returns the conversation collection of the tenant ctx is scoped to.
+func conversationCollection(ctx context.Context) *mongo.Collection {
+	return database.Collection(tenant.FromContext(ctx).ConversationCollection)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// CreateConversation starts a conversation for a user.
+func CreateConversation(ctx context.Context, userID, title string) (Conversation, error) {
+	conv := newConversation(userID, title)
+	if _, err := conversationCollection(ctx).InsertOne(ctx, conv); err != nil {
+		return Conversation{}, err
+	}
+	return conv, nil
+}
+
+// ListConversations returns a user's conversations, most recently active first.
+func ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	cursor, err := conversationCollection(ctx).Find(ctx, bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("Find() failed: %w", err)
+	}
+	var conversations []Conversation
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, fmt.Errorf("Decode() failed: %w", err)
+	}
+	return conversations, nil
+}
+
+// GetConversation returns one of a user's conversations, or ErrNotFound.
+func GetConversation(ctx context.Context, userID, conversationID string) (Conversation, error) {
+	var conv Conversation
+	err := conversationCollection(ctx).FindOne(ctx, bson.M{"_id": conversationID, "user_id": userID}).Decode(&conv)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Conversation{}, conversationNotFound(conversationID)
+	}
+	return conv, err
+}
+
+// RenameConversation sets a conversation's title and returns the result.
+func RenameConversation(ctx context.Context, userID, conversationID, title string) (Conversation, error) {
+	var conv Conversation
+	err := conversationCollection(ctx).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": conversationID, "user_id": userID},
+		bson.M{"$set": bson.M{"title": title}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&conv)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Conversation{}, conversationNotFound(conversationID)
+	}
+	return conv, err
+}
+
+// DeleteConversation removes a conversation with its messages and summary.
+func DeleteConversation(ctx context.Context, userID, conversationID string) error {
+	res, err := conversationCollection(ctx).DeleteOne(ctx, bson.M{"_id": conversationID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return conversationNotFound(conversationID)
+	}
+	if _, err := chatCollection(ctx).DeleteMany(ctx, bson.M{"user_id": userID, "conversation_id": conversationID}); err != nil {
+		return fmt.Errorf("failed to delete conversation messages: %w", err)
+	}
+	if _, err := summaryCollection(ctx).DeleteOne(ctx, summaryFilter(userID, conversationID)); err != nil {
+		return fmt.Errorf("failed to delete conversation summary: %w", err)
+	}
+	return nil
+}
+
+// EachConversation calls fn with every stored conversation of the tenant ctx
+// is scoped to, stopping at the first error.
+func EachConversation(ctx context.Context, fn func(Conversation) error) error {
+	cursor, err := conversationCollection(ctx).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var conv Conversation
+		if err := cursor.Decode(&conv); err != nil {
+			return err
+		}
+		if err := fn(conv); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// countConversationMessage bumps a conversation's message count and activity time.
+func countConversationMessage(ctx context.Context, userID, conversationID string, at time.Time) error {
+	_, err := conversationCollection(ctx).UpdateOne(
+		ctx,
+		bson.M{"_id": conversationID, "user_id": userID},
+		bson.M{"$inc": bson.M{"message_count": 1}, "$set": bson.M{"updated_at": at}},
+	)
+	return err
+}
+
+func conversationNotFound(conversationID string) error {
+	return fmt.Errorf("conversation %q %w", conversationID, ErrNotFound)
+}
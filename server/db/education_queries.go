@@ -11,7 +11,7 @@ import (
 // ─────────────────────────────────────────────────────────────────────────────
 
 // GetAllEducationSorted returns all education entries sorted by start date.
-func GetAllEducationSorted() ([]Education, error) {
+func GetAllEducationSorted(ctx context.Context) ([]Education, error) {
 	query := `
 		MATCH (e:Education)
 		RETURN 
@@ -26,14 +26,41 @@ func GetAllEducationSorted() ([]Education, error) {
 			COALESCE(e.leadership, []) AS leadership
 		ORDER BY e.startDate
 	`
+	return runEducationResultQuery(ctx, query, nil)
+}
+
+// SearchEducationByInstitution returns education nodes matching the institution.
+func SearchEducationByInstitution(ctx context.Context, institution string) ([]Education, error) {
+	query := `
+		MATCH (e:Education)
+		WHERE toLower(e.institution) CONTAINS toLower($institution)
+		RETURN e
+	`
+	params := map[string]interface{}{"institution": institution}
+	return queryEducations(ctx, query, params)
+}
+
+// SearchEducationByField returns education nodes matching the field.
+func SearchEducationByField(ctx context.Context, field string) ([]Education, error) {
+	query := `
+		MATCH (e:Education)
+		WHERE toLower(e.field) CONTAINS toLower($field)
+		RETURN e
+	`
+	params := map[string]interface{}{"field": field}
+	return queryEducations(ctx, query, params)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPER
+// ─────────────────────────────────────────────────────────────────────────────
 
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+// runEducationResultQuery maps column-based results (id, summary, ...) to full education entries.
+func runEducationResultQuery(ctx context.Context, query string, params map[string]interface{}) ([]Education, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
-	result, err := session.Run(ctx, query, nil)
+	result, err := session.Run(ctx, query, params)
 	if err != nil {
 		return nil, err
 	}
@@ -63,38 +90,9 @@ func GetAllEducationSorted() ([]Education, error) {
 	return educationList, nil
 }
 
-// SearchEducationByInstitution returns education nodes matching the institution.
-func SearchEducationByInstitution(institution string) ([]Education, error) {
-	query := `
-		MATCH (e:Education)
-		WHERE toLower(e.institution) CONTAINS toLower($institution)
-		RETURN e
-	`
-	params := map[string]interface{}{"institution": institution}
-	return queryEducations(query, params)
-}
-
-// SearchEducationByField returns education nodes matching the field.
-func SearchEducationByField(field string) ([]Education, error) {
-	query := `
-		MATCH (e:Education)
-		WHERE toLower(e.field) CONTAINS toLower($field)
-		RETURN e
-	`
-	params := map[string]interface{}{"field": field}
-	return queryEducations(query, params)
-}
-
-// ─────────────────────────────────────────────────────────────────────────────
-// INTERNAL HELPER
-// ─────────────────────────────────────────────────────────────────────────────
-
 // queryEducations executes a generic query and returns basic education data.
-func queryEducations(cypher string, params map[string]interface{}) ([]Education, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func queryEducations(ctx context.Context, cypher string, params map[string]interface{}) ([]Education, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, cypher, params)
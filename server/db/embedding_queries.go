@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// EmbeddableLabels are the node types indexed for semantic retrieval.
+var EmbeddableLabels = []string{"Project", "WorkExperience", "Education", "Hobby", "Skill"}
+
+// EmbeddableNode is a resume node together with the text used to embed it.
+type EmbeddableNode struct {
+	ElementID  string
+	Label      string
+	Key        string // id, or name for node types without one
+	Name       string
+	Text       string
+	Featured   bool
+	Link       string // demo or repository URL, if the node has one
+	Date       string // end date, or start date while ongoing
+	StoredHash string // hash of the text the stored embedding was built from
+}
+
+// Hash returns a stable fingerprint of the node's embedding text.
+func (n EmbeddableNode) Hash() string {
+	sum := sha256.Sum256([]byte(n.Text))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimilarNode is a node returned by a vector or full-text search with its score.
+type SimilarNode struct {
+	Label    string  `json:"label"`
+	Key      string  `json:"key"`
+	Name     string  `json:"name"`
+	Text     string  `json:"text"`
+	Featured bool    `json:"featured"`
+	Link     string  `json:"link,omitempty"`
+	Date     string  `json:"date,omitempty"`
+	Score    float64 `json:"score"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ListEmbeddableNodes returns every node of the embeddable types with its text.
+func ListEmbeddableNodes(ctx context.Context) ([]EmbeddableNode, error) {
+	var nodes []EmbeddableNode
+	for _, label := range EmbeddableLabels {
+		query := fmt.Sprintf(`
+			MATCH (n:%s)
+			RETURN elementId(n) AS elementId, n
+		`, label)
+
+		result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, query, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			var found []EmbeddableNode
+			for res.Next(ctx) {
+				record := res.Record()
+				val, _ := record.Get("n")
+				props := val.(neo4j.Node).Props
+				node := embeddableFromProps(label, props)
+				node.ElementID = asString(record, "elementId")
+				node.StoredHash = toString(props["embeddingHash"])
+				found = append(found, node)
+			}
+			return found, res.Err()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s nodes: %w", label, err)
+		}
+		nodes = append(nodes, result.([]EmbeddableNode)...)
+	}
+	return nodes, nil
+}
+
+// SetNodeEmbedding stores the vector and the hash of the text it was built from.
+func SetNodeEmbedding(ctx context.Context, elementID string, embedding []float64, hash string) error {
+	_, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (n)
+			WHERE elementId(n) = $elementId
+			SET n.embedding = $embedding, n.embeddingHash = $hash
+		`, map[string]any{"elementId": elementID, "embedding": embedding, "hash": hash})
+		return nil, err
+	})
+	return err
+}
+
+// EnsureVectorIndexes creates one cosine vector index per embeddable label.
+func EnsureVectorIndexes(ctx context.Context, dimensions int) error {
+	for _, label := range EmbeddableLabels {
+		// Index options can't be parameterised, so the (int) dimension is inlined
+		query := fmt.Sprintf(`
+			CREATE VECTOR INDEX %s IF NOT EXISTS
+			FOR (n:%s) ON (n.embedding)
+			OPTIONS { indexConfig: {
+				`+"`vector.dimensions`"+`: %d,
+				`+"`vector.similarity_function`"+`: 'cosine'
+			} }
+		`, vectorIndexName(label), label, dimensions)
+
+		if _, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, query, nil)
+			return nil, err
+		}); err != nil {
+			return fmt.Errorf("failed to create vector index for %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// SearchSimilarNodes returns the k nodes across all embeddable types whose
+// embeddings are closest to the given vector, best first.
+func SearchSimilarNodes(ctx context.Context, vector []float64, k int) ([]SimilarNode, error) {
+	var matches []SimilarNode
+	for _, label := range EmbeddableLabels {
+		result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, `
+				CALL db.index.vector.queryNodes($index, $k, $vector)
+				YIELD node, score
+				RETURN node, score
+			`, map[string]any{"index": vectorIndexName(label), "k": k, "vector": vector})
+			if err != nil {
+				return nil, err
+			}
+
+			var found []SimilarNode
+			for res.Next(ctx) {
+				record := res.Record()
+				val, _ := record.Get("node")
+				score, _ := record.Get("score")
+				node := embeddableFromProps(label, val.(neo4j.Node).Props)
+				found = append(found, node.similar(toFloat(score)))
+			}
+			return found, res.Err()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("vector search on %s failed: %w", label, err)
+		}
+		matches = append(matches, result.([]SimilarNode)...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (n EmbeddableNode) similar(score float64) SimilarNode {
+	return SimilarNode{
+		Label:    n.Label,
+		Key:      n.Key,
+		Name:     n.Name,
+		Text:     n.Text,
+		Featured: n.Featured,
+		Link:     n.Link,
+		Date:     n.Date,
+		Score:    score,
+	}
+}
+
+func vectorIndexName(label string) string {
+	return strings.ToLower(label) + "_embedding"
+}
+
+// embeddableFromProps builds the key, display name and embedding text for a node.
+func embeddableFromProps(label string, props map[string]any) EmbeddableNode {
+	node := EmbeddableNode{
+		Label:    label,
+		Key:      toString(props["id"]),
+		Featured: toBool(props["featured"]),
+		Date:     LatestDate(toString(props["startDate"]), toString(props["endDate"])),
+	}
+	var parts []string
+
+	switch label {
+	case "Project":
+		node.Name = toString(props["name"])
+		node.Link = Project{Demo: toString(props["demo"]), GitHub: toString(props["github"])}.Link()
+		parts = append(parts, node.Name, toString(props["description"]))
+		parts = append(parts, toStringSlice(props["contributions"])...)
+	case "WorkExperience":
+		node.Name = fmt.Sprintf("%s at %s", toString(props["title"]), toString(props["company"]))
+		parts = append(parts, node.Name, toString(props["summary"]))
+	case "Education":
+		node.Name = fmt.Sprintf("%s at %s", toString(props["degree"]), toString(props["institution"]))
+		parts = append(parts, node.Name, toString(props["field"]), toString(props["summary"]))
+		parts = append(parts, toStringSlice(props["leadership"])...)
+	case "Hobby":
+		node.Name = toString(props["name"])
+		parts = append(parts, node.Name, toString(props["description"]))
+	case "Skill":
+		node.Name = toString(props["name"])
+		parts = append(parts, node.Name)
+	}
+
+	if node.Key == "" {
+		node.Key = node.Name
+	}
+
+	var nonEmpty []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	node.Text = strings.Join(nonEmpty, "\n")
+	return node
+}
+
+func toFloat(val any) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
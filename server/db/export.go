@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ExportVersion is the version of the export document format. Bump it when a
+// change would stop older readers from understanding a document.
+const ExportVersion = 1
+
+// Export is a portable snapshot of a tenant's whole resume graph. Aliases are
+// kept beside the graph, by label, since they aren't model fields. Embeddings
+// are left out: they are derived, and `index` rebuilds them.
+type Export struct {
+	Version    int                     `json:"version"`
+	ExportedAt time.Time               `json:"exportedAt"`
+	Graph      ResumeGraph             `json:"graph"`
+	Aliases    map[string][]EntityName `json:"aliases,omitempty"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ExportGraph snapshots every resume node and relationship of the tenant ctx
+// is scoped to, in a stable order so exports diff cleanly. Only the tenant's
+// own Person is included.
+func ExportGraph(ctx context.Context) (Export, error) {
+	var g ResumeGraph
+	exported := map[NodeRef]bool{}
+
+	nodesOf := func(label string) ([]map[string]any, error) {
+		nodes, err := loadNodeProps(ctx, label)
+		if err != nil {
+			return nil, err
+		}
+		var props []map[string]any
+		for _, key := range slices.Sorted(maps.Keys(nodes)) {
+			exported[NodeRef{Label: label, Key: key}] = true
+			props = append(props, nodes[key])
+		}
+		return props, nil
+	}
+
+	if person, err := GetPerson(ctx); err == nil {
+		g.Person = person
+	}
+	projects, err := nodesOf("Project")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range projects {
+		g.Projects = append(g.Projects, projectFromProps(p))
+	}
+	work, err := nodesOf("WorkExperience")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range work {
+		g.WorkExperience = append(g.WorkExperience, workExperienceFromProps(p))
+	}
+	education, err := nodesOf("Education")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range education {
+		g.Education = append(g.Education, educationFromProps(p))
+	}
+	courses, err := nodesOf("Course")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range courses {
+		g.Courses = append(g.Courses, Course{ID: toString(p["id"]), Title: toString(p["title"]), Description: toString(p["description"])})
+	}
+	hobbies, err := nodesOf("Hobby")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range hobbies {
+		g.Hobbies = append(g.Hobbies, Hobby{Name: toString(p["name"]), Description: toString(p["description"])})
+	}
+	skills, err := nodesOf("Skill")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range skills {
+		g.Skills = append(g.Skills, Skill{Name: toString(p["name"])})
+	}
+	tags, err := nodesOf("Tag")
+	if err != nil {
+		return Export{}, err
+	}
+	for _, p := range tags {
+		g.Tags = append(g.Tags, Tag{Name: toString(p["name"])})
+	}
+
+	links, err := loadRelationships(ctx)
+	if err != nil {
+		return Export{}, err
+	}
+	for rel := range links {
+		if exported[rel.From] && exported[rel.To] {
+			g.Relationships = append(g.Relationships, rel)
+		}
+	}
+	slices.SortFunc(g.Relationships, compareRelationships)
+
+	export := Export{Version: ExportVersion, ExportedAt: time.Now().UTC(), Graph: g}
+	for _, label := range AliasLabels {
+		names, err := ListEntityNames(ctx, label)
+		if err != nil {
+			return Export{}, err
+		}
+		for _, n := range names {
+			if len(n.Aliases) == 0 {
+				continue
+			}
+			if export.Aliases == nil {
+				export.Aliases = map[string][]EntityName{}
+			}
+			export.Aliases[label] = append(export.Aliases[label], n)
+		}
+	}
+	return export, nil
+}
+
+// ReadExport decodes an export document, rejecting versions this build
+// doesn't understand.
+func ReadExport(r io.Reader) (Export, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Export{}, fmt.Errorf("invalid export: %w", err)
+	}
+	if export.Version < 1 || export.Version > ExportVersion {
+		return Export{}, fmt.Errorf("unsupported export version %d (this build reads up to %d)", export.Version, ExportVersion)
+	}
+	return export, nil
+}
+
+// RestoreGraph makes the stored graph mirror an export, aliases included, and
+// returns the changeset. With dryRun the changeset is only planned.
+func RestoreGraph(ctx context.Context, export Export, dryRun bool) (Changeset, error) {
+	cs, err := PlanChangeset(ctx, export.Graph, PlanRestore)
+	if err != nil || dryRun {
+		return cs, err
+	}
+	if err := ApplyChangeset(ctx, cs); err != nil {
+		return cs, err
+	}
+	for label, names := range export.Aliases {
+		for _, n := range names {
+			for _, alias := range n.Aliases {
+				if err := AddAlias(ctx, label, n.Name, alias); err != nil {
+					return cs, fmt.Errorf("failed to restore alias %q of %s %q: %w", alias, label, n.Name, err)
+				}
+			}
+		}
+	}
+	return cs, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// The parsers below tolerate missing properties, unlike the query-layer ones,
+// since an export must not fail on a half-filled node.
+
+func projectFromProps(p map[string]any) Project {
+	return Project{
+		ID:            toString(p["id"]),
+		Name:          toString(p["name"]),
+		Description:   toString(p["description"]),
+		Institution:   toString(p["institution"]),
+		Image:         toString(p["image"]),
+		Featured:      toBool(p["featured"]),
+		Contributions: toStringSlice(p["contributions"]),
+		StartDate:     toString(p["startDate"]),
+		EndDate:       toString(p["endDate"]),
+		Demo:          toString(p["demo"]),
+		GitHub:        toString(p["github"]),
+	}
+}
+
+func workExperienceFromProps(p map[string]any) WorkExperience {
+	return WorkExperience{
+		ID:        toString(p["id"]),
+		Summary:   toString(p["summary"]),
+		Company:   toString(p["company"]),
+		Title:     toString(p["title"]),
+		StartDate: toString(p["startDate"]),
+		EndDate:   toString(p["endDate"]),
+		Featured:  toBool(p["featured"]),
+	}
+}
+
+func educationFromProps(p map[string]any) Education {
+	return Education{
+		ID:          toString(p["id"]),
+		Summary:     toString(p["summary"]),
+		Institution: toString(p["institution"]),
+		Field:       toString(p["field"]),
+		Degree:      toString(p["degree"]),
+		Level:       toString(p["level"]),
+		StartDate:   toString(p["startDate"]),
+		EndDate:     toString(p["endDate"]),
+		Leadership:  toStringSlice(p["leadership"]),
+	}
+}
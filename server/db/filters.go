@@ -1,83 +1,61 @@
 package db
 
-import "log"
+import (
+	"context"
+	"strings"
+)
+
+// Filter operators. Clauses without an op are ANDed.
+const (
+	FilterAnd = "and"
+	FilterOr  = "or"
+	FilterNot = "not"
+)
 
 type FilterClause struct {
-	On       string `json:"on"`       // e.g., "Tag"
-	Value    string `json:"value"`    // e.g., "Hyperpad"
-	Relation string `json:"relation"` // e.g., "HAS_TAG"
+	On       string `json:"on"`           // e.g., "Tag"
+	Value    string `json:"value"`        // e.g., "Hyperpad"
+	Relation string `json:"relation"`     // e.g., "HAS_TAG"
+	Op       string `json:"op,omitempty"` // "and" (default), "or" or "not"
 }
 
-// FindProjectsWithFilters dispatches filter-based queries for projects
-func FindProjectsWithFilters(filters []FilterClause) ([]Project, error) {
-	for _, f := range filters {
-		switch {
-		case f.On == "Tag":
-			return FindProjectsByTag(f.Value)
-		case f.On == "Skill":
-			return FindProjectsBySkill(f.Value)
-		case f.On == "Hobby":
-			return FindProjectsByHobby(f.Value)
-		case f.On == "Name":
-			return SearchProjectsByName(f.Value)
-		default:
-			log.Printf("⚠️ Ignoring unsupported project filter: %+v\n", f)
-		}
+func (f FilterClause) op() string {
+	switch strings.ToLower(f.Op) {
+	case FilterOr:
+		return FilterOr
+	case FilterNot:
+		return FilterNot
+	default:
+		return FilterAnd
 	}
-	return GetAllProjectsSorted()
 }
 
-// FindWorkExperienceWithFilters supports filtering work experience by company
-func FindWorkExperienceWithFilters(filters []FilterClause) ([]WorkExperience, error) {
-	for _, f := range filters {
-		switch f.On {
-		case "Tag":
-			return FindWorkExperienceByTag(f.Value)
-		case "Company":
-			return SearchWorkExperiencesByCompany(f.Value)
-		case "Name":
-			return SearchWorkExperiencesByName(f.Value)
-		}
-	}
-	return GetAllWorkExperiences()
+// FindProjectsWithFilters applies every filter clause to projects in a single query
+func FindProjectsWithFilters(ctx context.Context, filters []FilterClause) ([]Project, error) {
+	q := buildFilterQuery(projectFilterTarget, filters)
+	return runProjectResultQuery(ctx, q.Text, q.Params)
 }
 
-// FindEducationWithFilters filters education (future: by institution, field, etc.)
-func FindEducationWithFilters(filters []FilterClause) ([]Education, error) {
-	log.Println("in education")
-	for _, f := range filters {
-		if f.On == "Institution" {
-			return SearchEducationByInstitution(f.Value)
-		}
-		if f.On == "Field" {
-			return SearchEducationByField(f.Value)
-		}
-	}
-	return GetAllEducationSorted()
+// FindWorkExperienceWithFilters applies every filter clause to work experience in a single query
+func FindWorkExperienceWithFilters(ctx context.Context, filters []FilterClause) ([]WorkExperience, error) {
+	q := buildFilterQuery(workExperienceFilterTarget, filters)
+	return queryWorkExperiences(ctx, q.Text, q.Params)
 }
 
-// FindHobbiesWithFilters filters hobbies (future: by name or tag)
-func FindHobbiesWithFilters(filters []FilterClause) ([]Hobby, error) {
-	for _, f := range filters {
-		if f.On == "Name" {
-			return SearchHobbiesByName(f.Value)
-		}
-		if f.On == "Tag" {
-			return SearchHobbiesByTag(f.Value)
-		}
-	}
-	return GetAllHobbies()
+// FindEducationWithFilters applies every filter clause to education in a single query
+func FindEducationWithFilters(ctx context.Context, filters []FilterClause) ([]Education, error) {
+	q := buildFilterQuery(educationFilterTarget, filters)
+	return runEducationResultQuery(ctx, q.Text, q.Params)
 }
 
-// FindSkillsWithFilters filters skills (future: by tag or project)
-func FindSkillsWithFilters(filters []FilterClause) ([]Skill, error) {
-	for _, f := range filters {
-		if f.On == "Name" {
-			return SearchSkillsByName(f.Value)
-		}
-		if f.On == "Tag" {
-			return SearchSkillsByTag(f.Value)
-		}
-	}
-	return GetAllSkillsSorted()
+// FindHobbiesWithFilters applies every filter clause to hobbies in a single query
+func FindHobbiesWithFilters(ctx context.Context, filters []FilterClause) ([]Hobby, error) {
+	q := buildFilterQuery(hobbyFilterTarget, filters)
+	return queryHobbies(ctx, q.Text, q.Params)
+}
+
+// FindSkillsWithFilters applies every filter clause to skills in a single query
+func FindSkillsWithFilters(ctx context.Context, filters []FilterClause) ([]Skill, error) {
+	q := buildFilterQuery(skillFilterTarget, filters)
+	return querySkills(ctx, q.Text, q.Params)
 }
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// filterFixture has projects covering each combination of tags and skills the
+// parity cases filter on.
+var filterFixture = ResumeGraph{
+	Projects: []Project{
+		{ID: "atlas", Name: "Atlas", StartDate: "2024-04"},
+		{ID: "beacon", Name: "Beacon", StartDate: "2024-03"},
+		{ID: "comet", Name: "Comet", StartDate: "2024-02"},
+		{ID: "drift", Name: "Drift", StartDate: "2024-01"},
+	},
+	Relationships: []Relationship{
+		{Type: "HAS_TAG", From: NodeRef{"Project", "atlas"}, To: NodeRef{"Tag", "Backend"}},
+		{Type: "USES", From: NodeRef{"Project", "atlas"}, To: NodeRef{"Skill", "Go"}},
+		{Type: "HAS_TAG", From: NodeRef{"Project", "beacon"}, To: NodeRef{"Tag", "Frontend"}},
+		{Type: "USES", From: NodeRef{"Project", "beacon"}, To: NodeRef{"Skill", "React"}},
+		{Type: "HAS_TAG", From: NodeRef{"Project", "comet"}, To: NodeRef{"Tag", "Backend"}},
+		{Type: "HAS_TAG", From: NodeRef{"Project", "comet"}, To: NodeRef{"Tag", "Frontend"}},
+		{Type: "USES", From: NodeRef{"Project", "comet"}, To: NodeRef{"Skill", "Go"}},
+		{Type: "USES", From: NodeRef{"Project", "comet"}, To: NodeRef{"Skill", "React"}},
+	},
+}
+
+// TestFilterParity checks that the in-memory filters combine clauses the way
+// the Cypher buildFilterQuery generates does. Each clause is matched on its
+// own through the memory repository, and the query's WHERE clause is then
+// evaluated with those results in place of its predicates.
+func TestFilterParity(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryRepository(Export{Graph: filterFixture})
+
+	tests := []struct {
+		name    string
+		filters []FilterClause
+		want    []string
+	}{
+		{"no filters", nil, []string{"Atlas", "Beacon", "Comet", "Drift"}},
+		{"and", []FilterClause{{On: "Tag", Value: "backend"}}, []string{"Atlas", "Comet"}},
+		{"and and", []FilterClause{{On: "Tag", Value: "Backend"}, {On: "Skill", Value: "React", Op: "and"}}, []string{"Comet"}},
+		{"or", []FilterClause{{On: "Skill", Value: "Go", Op: "or"}, {On: "Skill", Value: "React", Op: "OR"}}, []string{"Atlas", "Beacon", "Comet"}},
+		{"not", []FilterClause{{On: "Skill", Value: "React", Op: "not"}}, []string{"Atlas", "Drift"}},
+		{"and not", []FilterClause{{On: "Tag", Value: "Backend"}, {On: "Skill", Value: "React", Op: "not"}}, []string{"Atlas"}},
+		{"and or", []FilterClause{{On: "Skill", Value: "Go"}, {On: "Tag", Value: "Frontend", Op: "or"}, {On: "Name", Value: "atl", Op: "or"}}, []string{"Atlas", "Comet"}},
+		{"and before or group", []FilterClause{{On: "Tag", Value: "Backend"}, {On: "Name", Value: "Beacon", Op: "or"}, {On: "Name", Value: "Drift", Op: "or"}}, nil},
+		{"or not", []FilterClause{{On: "Tag", Value: "Frontend", Op: "or"}, {On: "Name", Value: "Atlas", Op: "or"}, {On: "Name", Value: "Comet", Op: "not"}}, []string{"Atlas", "Beacon"}},
+		{"unknown op is and", []FilterClause{{On: "Tag", Value: "Frontend", Op: "xor"}}, []string{"Beacon", "Comet"}},
+		{"unsupported filter skipped", []FilterClause{{On: "Field", Value: "Physics"}, {On: "Name", Value: "Drift", Op: "not"}}, []string{"Atlas", "Beacon", "Comet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := m.FindProjectsWithFilters(ctx, tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range projects {
+				got = append(got, p.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("memory filters kept %v, want %v", got, tt.want)
+			}
+
+			q := buildFilterQuery(projectFilterTarget, tt.filters)
+			for _, p := range filterFixture.Projects {
+				cypher, err := evalWhere(q, len(tt.filters), func(i int) bool {
+					single, _ := m.FindProjectsWithFilters(ctx, []FilterClause{{On: tt.filters[i].On, Value: tt.filters[i].Value}})
+					return slices.ContainsFunc(single, func(s Project) bool { return s.ID == p.ID })
+				})
+				if err != nil {
+					t.Fatalf("%v in query:\n%s", err, q.Text)
+				}
+				if kept := slices.Contains(tt.want, p.Name); cypher != kept {
+					t.Errorf("Cypher WHERE is %t for %s, memory filters keep it: %t\n%s", cypher, p.Name, kept, q.Text)
+				}
+			}
+		})
+	}
+}
+
+// evalWhere evaluates the WHERE clause of a query built from n filters with
+// each predicate on $f<i> replaced by hit(i). A query without one matches
+// every node.
+func evalWhere(q cypherQuery, n int, hit func(i int) bool) (bool, error) {
+	_, where, ok := strings.Cut(q.Text, "WHERE ")
+	if !ok {
+		return true, nil
+	}
+	where, _, _ = strings.Cut(where, "RETURN")
+
+	// Substitute predicates from the highest index down so $f1 doesn't match $f10
+	for i := n - 1; i >= 0; i-- {
+		param := fmt.Sprintf("$f%d", i)
+		if _, ok := q.Params[param[1:]]; !ok {
+			continue
+		}
+		var predicate string
+		for _, tmpl := range projectFilterTarget.Predicates {
+			if p := strings.ReplaceAll(tmpl, "$value", param); strings.Contains(where, p) {
+				predicate = p
+				break
+			}
+		}
+		if predicate == "" {
+			return false, fmt.Errorf("no predicate uses %s", param)
+		}
+		where = strings.Replace(where, predicate, fmt.Sprint(hit(i)), 1)
+	}
+
+	p := &boolParser{tokens: strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(where))}
+	result, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return result, err
+}
+
+// boolParser evaluates true/false expressions joined by NOT, AND, OR and
+// parentheses, with Cypher's precedence.
+type boolParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *boolParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *boolParser) or() (bool, error) {
+	left, err := p.and()
+	for err == nil && p.next() == "OR" {
+		p.pos++
+		var right bool
+		right, err = p.and()
+		left = left || right
+	}
+	return left, err
+}
+
+func (p *boolParser) and() (bool, error) {
+	left, err := p.not()
+	for err == nil && p.next() == "AND" {
+		p.pos++
+		var right bool
+		right, err = p.not()
+		left = left && right
+	}
+	return left, err
+}
+
+func (p *boolParser) not() (bool, error) {
+	switch token := p.next(); token {
+	case "NOT":
+		p.pos++
+		v, err := p.not()
+		return !v, err
+	case "(":
+		p.pos++
+		v, err := p.or()
+		if err == nil && p.next() != ")" {
+			err = fmt.Errorf("unclosed parenthesis")
+		}
+		p.pos++
+		return v, err
+	case "true", "false":
+		p.pos++
+		return token == "true", nil
+	default:
+		return false, fmt.Errorf("unexpected %q", token)
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const fullTextIndexName = "resume_fulltext"
+
+// luceneSpecial are characters with meaning in Lucene query syntax.
+const luceneSpecial = `+-&|!(){}[]^"~*?:\/`
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// EnsureFullTextIndex creates the keyword index over the text fields of all
+// embeddable node types.
+func EnsureFullTextIndex(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE FULLTEXT INDEX %s IF NOT EXISTS
+		FOR (n:%s)
+		ON EACH [n.name, n.description, n.summary, n.title, n.company, n.institution, n.field]
+	`, fullTextIndexName, strings.Join(EmbeddableLabels, "|"))
+
+	_, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, query, nil)
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create full-text index: %w", err)
+	}
+	return nil
+}
+
+// FullTextSearch returns up to k nodes matching any keyword in text, best first.
+func FullTextSearch(ctx context.Context, text string, k int) ([]SimilarNode, error) {
+	query := toLuceneQuery(text)
+	if query == "" {
+		return nil, nil
+	}
+
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			CALL db.index.fulltext.queryNodes($index, $query)
+			YIELD node, score
+			RETURN labels(node)[0] AS label, node, score
+			LIMIT $k
+		`, map[string]any{"index": fullTextIndexName, "query": query, "k": k})
+		if err != nil {
+			return nil, err
+		}
+
+		var found []SimilarNode
+		for res.Next(ctx) {
+			record := res.Record()
+			val, _ := record.Get("node")
+			score, _ := record.Get("score")
+			node := embeddableFromProps(asString(record, "label"), val.(neo4j.Node).Props)
+			found = append(found, node.similar(toFloat(score)))
+		}
+		return found, res.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("full-text search failed: %w", err)
+	}
+	return result.([]SimilarNode), nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPER
+// ─────────────────────────────────────────────────────────────────────────────
+
+// toLuceneQuery escapes each word of free text and ORs them together. Very
+// short words are dropped since they mostly match noise.
+func toLuceneQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		word = strings.Trim(word, ".,!?;:'\"")
+		if len([]rune(word)) < 3 {
+			continue
+		}
+		var b strings.Builder
+		for _, r := range word {
+			if strings.ContainsRune(luceneSpecial, r) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+		}
+		terms = append(terms, b.String())
+	}
+	return strings.Join(terms, " OR ")
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
@@ -72,6 +74,9 @@ func safeString(val any) string {
 
 // Helper to safely convert []any to []string
 func toStringSlice(val any) []string {
+	if strs, ok := val.([]string); ok {
+		return strs
+	}
 	items, ok := val.([]any)
 	if !ok {
 		return nil
@@ -133,12 +138,48 @@ func toBool(value any) bool {
 	}
 	return false
 }
-func withReadSession(run func(tx neo4j.ManagedTransaction) (any, error)) (any, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func withReadSession(ctx context.Context, run func(tx neo4j.ManagedTransaction) (any, error)) (any, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	return session.ExecuteRead(ctx, run)
 }
+
+func withWriteSession(ctx context.Context, run func(tx neo4j.ManagedTransaction) (any, error)) (any, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeWrite))
+	defer session.Close(ctx)
+
+	return session.ExecuteWrite(ctx, run)
+}
+
+// LatestDate returns the end date, or the start date when there is no end date.
+func LatestDate(start, end string) string {
+	if end != "" {
+		return end
+	}
+	return start
+}
+
+// FirstNonEmpty returns the first value that isn't blank, or "".
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// DateLayouts are the date formats resume nodes use.
+var DateLayouts = []string{"2006-01-02", "2006-01", "January 2006", "Jan 2006", "01/2006", "2006"}
+
+// ParseDate parses a resume date in any of the DateLayouts.
+func ParseDate(date string) (time.Time, bool) {
+	date = strings.TrimSpace(date)
+	for _, layout := range DateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
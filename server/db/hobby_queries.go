@@ -11,34 +11,34 @@ import (
 // ─────────────────────────────────────────────────────────────────────────────
 
 // GetAllHobbies returns all Hobby nodes sorted by name.
-func GetAllHobbies() ([]Hobby, error) {
+func GetAllHobbies(ctx context.Context) ([]Hobby, error) {
 	query := `
 		MATCH (h:Hobby)
 		RETURN h
 		ORDER BY h.name
 	`
-	return queryHobbies(query, nil)
+	return queryHobbies(ctx, query, nil)
 }
 
 // SearchHobbiesByName returns hobbies where the name partially matches the input (case-insensitive).
-func SearchHobbiesByName(name string) ([]Hobby, error) {
+func SearchHobbiesByName(ctx context.Context, name string) ([]Hobby, error) {
 	query := `
 		MATCH (h:Hobby)
 		WHERE toLower(h.name) CONTAINS toLower($name)
 		RETURN h
 	`
 	params := map[string]interface{}{"name": name}
-	return queryHobbies(query, params)
+	return queryHobbies(ctx, query, params)
 }
 
 // FindHobbiesByTag returns hobbies associated with a specific tag.
-func SearchHobbiesByTag(tag string) ([]Hobby, error) {
+func SearchHobbiesByTag(ctx context.Context, tag string) ([]Hobby, error) {
 	query := `
 		MATCH (h:Hobby)-[:HAS_TAG]->(t:Tag {name: $tag})
 		RETURN h
 	`
 	params := map[string]interface{}{"tag": tag}
-	return queryHobbies(query, params)
+	return queryHobbies(ctx, query, params)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -46,11 +46,8 @@ func SearchHobbiesByTag(tag string) ([]Hobby, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // queryHobbies runs a Cypher query and returns a slice of Hobby nodes.
-func queryHobbies(cypher string, params map[string]interface{}) ([]Hobby, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func queryHobbies(ctx context.Context, cypher string, params map[string]interface{}) ([]Hobby, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, cypher, params)
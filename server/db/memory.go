@@ -0,0 +1,579 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go-ai/tenant"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	_ ResumeRepository = (*MemoryRepository)(nil)
+	_ ChatStore        = (*MemoryChatStore)(nil)
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// RESUME REPOSITORY
+// ─────────────────────────────────────────────────────────────────────────────
+
+// MemoryRepository serves a resume graph held in memory, typically a fixture
+// written by the `export` command. Filters behave like the Cypher ones in
+// query_builder.go. Every tenant sees the same graph, and there are no
+// embeddings, so semantic search finds nothing.
+type MemoryRepository struct {
+	graph   ResumeGraph
+	aliases map[string][]EntityName
+	work    map[string]WorkExperience
+}
+
+// NewMemoryRepository serves the graph and aliases of an export document.
+func NewMemoryRepository(export Export) *MemoryRepository {
+	m := &MemoryRepository{
+		graph:   export.Graph,
+		aliases: export.Aliases,
+		work:    map[string]WorkExperience{},
+	}
+	for _, w := range export.Graph.WorkExperience {
+		m.work[w.ID] = w
+	}
+	return m
+}
+
+// LoadMemoryRepository reads an export document from path and serves it.
+func LoadMemoryRepository(path string) (*MemoryRepository, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	export, err := ReadExport(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewMemoryRepository(export), nil
+}
+
+func (m *MemoryRepository) GetPerson(context.Context) (*Person, error) {
+	if m.graph.Person == nil {
+		return nil, fmt.Errorf("no person node found")
+	}
+	person := *m.graph.Person
+	return &person, nil
+}
+
+// Schema lists the labels that have nodes and the relationships that connect
+// them, formatted like LoadGraphSchemaOnce does.
+func (m *MemoryRepository) Schema(context.Context) GraphSchema {
+	var schema GraphSchema
+	for _, n := range m.graph.Nodes() {
+		if !slices.Contains(schema.NodeLabels, n.Label()) {
+			schema.NodeLabels = append(schema.NodeLabels, n.Label())
+		}
+	}
+	for _, r := range m.graph.Relationships {
+		rel := fmt.Sprintf("(%s)-[:%s]->(%s)", r.From.Label, r.Type, r.To.Label)
+		if !slices.Contains(schema.Relationships, rel) {
+			schema.Relationships = append(schema.Relationships, rel)
+		}
+	}
+	slices.Sort(schema.NodeLabels)
+	slices.Sort(schema.Relationships)
+	return schema
+}
+
+func (m *MemoryRepository) FindProjectsWithFilters(_ context.Context, filters []FilterClause) ([]Project, error) {
+	ref := func(p Project) NodeRef { return NodeRef{Label: "Project", Key: p.ID} }
+	predicates := map[string]func(Project, string) bool{
+		"Tag":   func(p Project, v string) bool { return m.linksTo(ref(p), "HAS_TAG", equalFold(v)) },
+		"Skill": func(p Project, v string) bool { return m.linksTo(ref(p), "USES", equalFold(v)) },
+		"Hobby": func(p Project, v string) bool { return m.linkedFrom(ref(p), "INSPIRED", equalFold(v)) },
+		"Name":  func(p Project, v string) bool { return containsFold(p.Name, v) },
+		"Company": func(p Project, v string) bool {
+			return m.linksTo(ref(p), "WORKED_ON", func(id string) bool { return containsFold(m.work[id].Company, v) })
+		},
+		"Institution": func(p Project, v string) bool { return containsFold(p.Institution, v) },
+	}
+	projects := filterNodes("Project", m.graph.Projects, predicates, filters)
+	slices.SortStableFunc(projects, func(a, b Project) int { return cmp.Compare(b.StartDate, a.StartDate) })
+	return projects, nil
+}
+
+func (m *MemoryRepository) FindWorkExperienceWithFilters(_ context.Context, filters []FilterClause) ([]WorkExperience, error) {
+	ref := func(w WorkExperience) NodeRef { return NodeRef{Label: "WorkExperience", Key: w.ID} }
+	predicates := map[string]func(WorkExperience, string) bool{
+		"Tag": func(w WorkExperience, v string) bool { return m.linksTo(ref(w), "HAS_TAG", equalFold(v)) },
+		"Skill": func(w WorkExperience, v string) bool {
+			return m.linkedFrom(ref(w), "WORKED_ON", func(project string) bool {
+				return m.linksTo(NodeRef{Label: "Project", Key: project}, "USES", equalFold(v))
+			})
+		},
+		"Company": func(w WorkExperience, v string) bool { return containsFold(w.Company, v) },
+		"Name":    func(w WorkExperience, v string) bool { return containsFold(w.Company, v) || containsFold(w.Title, v) },
+	}
+	work := filterNodes("WorkExperience", m.graph.WorkExperience, predicates, filters)
+	slices.SortStableFunc(work, func(a, b WorkExperience) int { return cmp.Compare(a.StartDate, b.StartDate) })
+	return work, nil
+}
+
+func (m *MemoryRepository) FindEducationWithFilters(_ context.Context, filters []FilterClause) ([]Education, error) {
+	ref := func(e Education) NodeRef { return NodeRef{Label: "Education", Key: e.ID} }
+	predicates := map[string]func(Education, string) bool{
+		"Tag":         func(e Education, v string) bool { return m.linksTo(ref(e), "HAS_TAG", equalFold(v)) },
+		"Institution": func(e Education, v string) bool { return containsFold(e.Institution, v) },
+		"Field":       func(e Education, v string) bool { return containsFold(e.Field, v) },
+		"Name":        func(e Education, v string) bool { return containsFold(e.Degree, v) || containsFold(e.Institution, v) },
+	}
+	education := filterNodes("Education", m.graph.Education, predicates, filters)
+	slices.SortStableFunc(education, func(a, b Education) int { return cmp.Compare(a.StartDate, b.StartDate) })
+	return education, nil
+}
+
+func (m *MemoryRepository) FindHobbiesWithFilters(_ context.Context, filters []FilterClause) ([]Hobby, error) {
+	predicates := map[string]func(Hobby, string) bool{
+		"Tag": func(h Hobby, v string) bool {
+			return m.linksTo(NodeRef{Label: "Hobby", Key: h.Name}, "HAS_TAG", equalFold(v))
+		},
+		"Name": func(h Hobby, v string) bool { return containsFold(h.Name, v) },
+	}
+	hobbies := filterNodes("Hobby", m.graph.Hobbies, predicates, filters)
+	slices.SortStableFunc(hobbies, func(a, b Hobby) int { return cmp.Compare(a.Name, b.Name) })
+	return hobbies, nil
+}
+
+func (m *MemoryRepository) FindSkillsWithFilters(_ context.Context, filters []FilterClause) ([]Skill, error) {
+	predicates := map[string]func(Skill, string) bool{
+		"Tag": func(s Skill, v string) bool {
+			return m.linkedFrom(NodeRef{Label: "Skill", Key: s.Name}, "USES", func(project string) bool {
+				return m.linksTo(NodeRef{Label: "Project", Key: project}, "HAS_TAG", equalFold(v))
+			})
+		},
+		"Name": func(s Skill, v string) bool { return containsFold(s.Name, v) },
+	}
+	skills := filterNodes("Skill", m.graph.Skills, predicates, filters)
+	slices.SortStableFunc(skills, func(a, b Skill) int { return cmp.Compare(a.Name, b.Name) })
+	return skills, nil
+}
+
+func (m *MemoryRepository) ListProjectNames(ctx context.Context) ([]string, error) {
+	projects, _ := m.FindProjectsWithFilters(ctx, nil)
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
+
+func (m *MemoryRepository) ListWorkExperienceCompanies(ctx context.Context) ([]string, error) {
+	work, _ := m.FindWorkExperienceWithFilters(ctx, nil)
+	var companies []string
+	for _, w := range work {
+		companies = append(companies, w.Company)
+	}
+	return companies, nil
+}
+
+func (m *MemoryRepository) GetAllSkillsSorted(ctx context.Context) ([]Skill, error) {
+	return m.FindSkillsWithFilters(ctx, nil)
+}
+
+func (m *MemoryRepository) GetAllTagsSorted(context.Context) ([]Tag, error) {
+	tags := slices.Clone(m.graph.Tags)
+	slices.SortStableFunc(tags, func(a, b Tag) int { return cmp.Compare(a.Name, b.Name) })
+	return tags, nil
+}
+
+func (m *MemoryRepository) GetAllHobbies(ctx context.Context) ([]Hobby, error) {
+	return m.FindHobbiesWithFilters(ctx, nil)
+}
+
+// ListEntityNames returns every name of one of the AliasLabels, with the
+// aliases the export carried for it.
+func (m *MemoryRepository) ListEntityNames(_ context.Context, label string) ([]EntityName, error) {
+	if !slices.Contains(AliasLabels, label) {
+		return nil, fmt.Errorf("%s nodes don't carry aliases", label)
+	}
+	aliases := map[string][]string{}
+	for _, n := range m.aliases[label] {
+		aliases[n.Name] = n.Aliases
+	}
+
+	var names []EntityName
+	for _, n := range m.graph.Nodes() {
+		if n.Label() == label {
+			names = append(names, EntityName{Name: n.Key(), Aliases: aliases[n.Key()]})
+		}
+	}
+	slices.SortFunc(names, func(a, b EntityName) int { return cmp.Compare(a.Name, b.Name) })
+	return names, nil
+}
+
+// FullTextSearch scores each embeddable node by how many of the words in text
+// (three letters or longer) it contains and returns up to k, best first.
+func (m *MemoryRepository) FullTextSearch(_ context.Context, text string, k int) ([]SimilarNode, error) {
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var matches []SimilarNode
+	for _, n := range m.graph.Nodes() {
+		if !slices.Contains(EmbeddableLabels, n.Label()) {
+			continue
+		}
+		node := embeddableFromProps(n.Label(), n.Properties())
+		words := searchTerms(node.Text)
+		score := 0
+		for _, term := range terms {
+			if slices.Contains(words, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, node.similar(float64(score)))
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b SimilarNode) int { return cmp.Compare(b.Score, a.Score) })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// SearchSimilarNodes finds nothing: exports carry no embeddings.
+func (m *MemoryRepository) SearchSimilarNodes(context.Context, []float64, int) ([]SimilarNode, error) {
+	return nil, nil
+}
+
+// linksTo reports whether from has a relType relationship to a node whose
+// key matches.
+func (m *MemoryRepository) linksTo(from NodeRef, relType string, match func(key string) bool) bool {
+	for _, r := range m.graph.Relationships {
+		if r.Type == relType && r.From == from && match(r.To.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkedFrom reports whether a node whose key matches has a relType
+// relationship to to.
+func (m *MemoryRepository) linkedFrom(to NodeRef, relType string, match func(key string) bool) bool {
+	for _, r := range m.graph.Relationships {
+		if r.Type == relType && r.To == to && match(r.From.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CHAT STORE
+// ─────────────────────────────────────────────────────────────────────────────
+
+// MemoryChatStore keeps chat history in memory, per tenant and user. It is
+// lost when the process exits.
+type MemoryChatStore struct {
+	mu            sync.Mutex
+	messages      map[string][]ChatMessage
+	summaries     map[string]ConversationSummary
+	conversations map[string][]Conversation
+}
+
+func NewMemoryChatStore() *MemoryChatStore {
+	return &MemoryChatStore{
+		messages:      map[string][]ChatMessage{},
+		summaries:     map[string]ConversationSummary{},
+		conversations: map[string][]Conversation{},
+	}
+}
+
+func (s *MemoryChatStore) StoreMessage(ctx context.Context, userID string, msg ChatMessage) (string, error) {
+	msg.UserID = userID
+	msg.Timestamp = time.Now()
+	if msg.ID.IsZero() {
+		msg.ID = primitive.NewObjectID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	s.messages[key] = append(s.messages[key], msg)
+	if i := s.conversationIndex(key, msg.ConversationID); i >= 0 {
+		s.conversations[key][i].MessageCount++
+		s.conversations[key][i].UpdatedAt = msg.Timestamp
+	}
+	return msg.ID.Hex(), nil
+}
+
+func (s *MemoryChatStore) GetMessages(ctx context.Context, userID string) ([]ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages[chatKey(ctx, userID)]), nil
+}
+
+func (s *MemoryChatStore) GetConversationMessages(ctx context.Context, userID, conversationID string, q ConversationQuery) ([]ChatMessage, error) {
+	messages := s.conversationMessages(ctx, userID, conversationID)
+	end := max(len(messages)-q.Offset, 0)
+	start := 0
+	if q.Limit > 0 {
+		start = max(end-q.Limit, 0)
+	}
+	return messages[start:end], nil
+}
+
+func (s *MemoryChatStore) CountConversationMessages(ctx context.Context, userID, conversationID string) (int, error) {
+	return len(s.conversationMessages(ctx, userID, conversationID)), nil
+}
+
+func (s *MemoryChatStore) GetHistory(ctx context.Context, userID string, q HistoryQuery) (HistoryPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.messages[chatKey(ctx, userID)]
+
+	// Messages are kept in the order they were stored; narrow to the window
+	// strictly between the cursors, then take its end nearest the anchor
+	lo, hi := 0, len(messages)
+	for _, cursor := range []struct {
+		id    string
+		after bool
+	}{{q.Before, false}, {q.After, true}} {
+		if cursor.id == "" {
+			continue
+		}
+		i := slices.IndexFunc(messages, func(msg ChatMessage) bool { return msg.ID.Hex() == cursor.id })
+		if i < 0 {
+			return HistoryPage{}, messageNotFound(cursor.id)
+		}
+		if cursor.after {
+			lo = max(lo, i+1)
+		} else {
+			hi = min(hi, i)
+		}
+	}
+	if lo > hi {
+		lo = hi
+	}
+
+	if q.After != "" {
+		return newHistoryPage(slices.Clone(messages[lo:min(hi, lo+q.Limit+1)]), q.Limit, true), nil
+	}
+	window := slices.Clone(messages[max(lo, hi-q.Limit-1):hi])
+	slices.Reverse(window)
+	return newHistoryPage(window, q.Limit, false), nil
+}
+
+func (s *MemoryChatStore) GetSummary(ctx context.Context, userID, conversationID string) (*ConversationSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary, ok := s.summaries[chatKey(ctx, userID)+"/"+conversationID]
+	if !ok {
+		return nil, nil
+	}
+	return &summary, nil
+}
+
+func (s *MemoryChatStore) StoreSummary(ctx context.Context, summary ConversationSummary) error {
+	summary.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaries[chatKey(ctx, summary.UserID)+"/"+summary.ConversationID] = summary
+	return nil
+}
+
+func (s *MemoryChatStore) CreateConversation(ctx context.Context, userID, title string) (Conversation, error) {
+	conv := newConversation(userID, title)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	s.conversations[key] = append(s.conversations[key], conv)
+	return conv, nil
+}
+
+func (s *MemoryChatStore) ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversations := slices.Clone(s.conversations[chatKey(ctx, userID)])
+	slices.SortStableFunc(conversations, func(a, b Conversation) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return conversations, nil
+}
+
+func (s *MemoryChatStore) GetConversation(ctx context.Context, userID, conversationID string) (Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	i := s.conversationIndex(key, conversationID)
+	if i < 0 {
+		return Conversation{}, conversationNotFound(conversationID)
+	}
+	return s.conversations[key][i], nil
+}
+
+func (s *MemoryChatStore) RenameConversation(ctx context.Context, userID, conversationID, title string) (Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	i := s.conversationIndex(key, conversationID)
+	if i < 0 {
+		return Conversation{}, conversationNotFound(conversationID)
+	}
+	s.conversations[key][i].Title = title
+	return s.conversations[key][i], nil
+}
+
+func (s *MemoryChatStore) DeleteConversation(ctx context.Context, userID, conversationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	i := s.conversationIndex(key, conversationID)
+	if i < 0 {
+		return conversationNotFound(conversationID)
+	}
+	s.conversations[key] = slices.Delete(s.conversations[key], i, i+1)
+	s.messages[key] = slices.DeleteFunc(s.messages[key], func(msg ChatMessage) bool {
+		return msg.ConversationID == conversationID
+	})
+	delete(s.summaries, key+"/"+conversationID)
+	return nil
+}
+
+func (s *MemoryChatStore) DeleteUserHistory(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := chatKey(ctx, userID)
+	delete(s.messages, key)
+	delete(s.conversations, key)
+	for summaryKey, summary := range s.summaries {
+		if summary.UserID == userID && strings.HasPrefix(summaryKey, key+"/") {
+			delete(s.summaries, summaryKey)
+		}
+	}
+	return nil
+}
+
+// Prune deletes the messages of the tenant ctx is scoped to sent before the
+// cutoff, and the conversations and summaries last updated before it. It
+// returns how many messages were deleted.
+func (s *MemoryChatStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := tenant.FromContext(ctx).ID + "/"
+	pruned := 0
+	for key, messages := range s.messages {
+		if strings.HasPrefix(key, prefix) {
+			kept := slices.DeleteFunc(messages, func(msg ChatMessage) bool { return msg.Timestamp.Before(before) })
+			pruned += len(messages) - len(kept)
+			s.messages[key] = kept
+		}
+	}
+	for key, conversations := range s.conversations {
+		if strings.HasPrefix(key, prefix) {
+			s.conversations[key] = slices.DeleteFunc(conversations, func(c Conversation) bool { return c.UpdatedAt.Before(before) })
+		}
+	}
+	for key, summary := range s.summaries {
+		if strings.HasPrefix(key, prefix) && summary.UpdatedAt.Before(before) {
+			delete(s.summaries, key)
+		}
+	}
+	return pruned, nil
+}
+
+// conversationMessages returns a copy of the messages of one of a user's
+// conversations, oldest first.
+func (s *MemoryChatStore) conversationMessages(ctx context.Context, userID, conversationID string) []ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []ChatMessage
+	for _, msg := range s.messages[chatKey(ctx, userID)] {
+		if msg.ConversationID == conversationID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// conversationIndex returns the position of a conversation in a user's list,
+// or -1. The caller holds the lock.
+func (s *MemoryChatStore) conversationIndex(key, conversationID string) int {
+	if conversationID == "" {
+		return -1
+	}
+	return slices.IndexFunc(s.conversations[key], func(c Conversation) bool { return c.ID == conversationID })
+}
+
+// chatKey scopes a user's history to the tenant ctx is scoped to.
+func chatKey(ctx context.Context, userID string) string {
+	return tenant.FromContext(ctx).ID + "/" + userID
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// filterNodes keeps the nodes that satisfy filters the way buildFilterQuery
+// combines them: every "and" clause, at least one "or" clause (if any) and no
+// "not" clause. Filters the node type can't apply are skipped.
+func filterNodes[T any](label string, nodes []T, predicates map[string]func(T, string) bool, filters []FilterClause) []T {
+	var clauses []FilterClause
+	for _, f := range filters {
+		if _, ok := predicates[f.On]; !ok {
+			log.Printf("⚠️ Ignoring unsupported %s filter: %+v\n", label, f)
+			continue
+		}
+		clauses = append(clauses, f)
+	}
+
+	var kept []T
+	for _, n := range nodes {
+		hasOr, matchedOr, ok := false, false, true
+		for _, f := range clauses {
+			hit := predicates[f.On](n, f.Value)
+			switch f.op() {
+			case FilterOr:
+				hasOr = true
+				matchedOr = matchedOr || hit
+			case FilterNot:
+				ok = ok && !hit
+			default:
+				ok = ok && hit
+			}
+		}
+		if ok && (!hasOr || matchedOr) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+func equalFold(value string) func(string) bool {
+	return func(key string) bool { return strings.EqualFold(key, value) }
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// searchTerms lowercases text and splits it into words of three letters or
+// more, the same words toLuceneQuery searches for.
+func searchTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, ".,!?;:'\"()")
+		if len([]rune(word)) >= 3 {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
@@ -61,6 +61,14 @@ type Project struct {
 	GitHub        string   `json:"github,omitempty"`
 }
 
+// Link returns the project's demo URL, or its GitHub URL when there is no demo.
+func (p Project) Link() string {
+	if p.Demo != "" {
+		return p.Demo
+	}
+	return p.GitHub
+}
+
 // 🧩 SKILL
 type Skill struct {
 	Name string `json:"name"`
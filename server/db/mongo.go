@@ -2,32 +2,83 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-ai/config"
+	"go-ai/tenant"
 	"log"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ChatMessage struct {
-	UserID    string    `bson:"user_id,omitempty" json:"-"`
-	Role      string    `bson:"role" json:"role"`
-	Content   string    `bson:"content" json:"content"`
-	Timestamp time.Time `bson:"timestamp,omitempty" json:"-"`
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id,omitempty" json:"-"`
+	Role      string             `bson:"role" json:"role"`
+	Content   string             `bson:"content" json:"content"`
+	Timestamp time.Time          `bson:"timestamp,omitempty" json:"timestamp"`
+	// ConversationID is the conversation the message belongs to; empty for
+	// history from before conversations existed
+	ConversationID string `bson:"conversation_id,omitempty" json:"conversationId,omitempty"`
+
+	// RewrittenQuery records the standalone question a follow-up was planned as
+	RewrittenQuery string `bson:"rewritten_query,omitempty" json:"-"`
+	// PlanTrace records how the graph plan for this question was produced
+	PlanTrace *PlanTrace `bson:"plan_trace,omitempty" json:"-"`
+	// Sources lists the graph nodes an assistant reply cited
+	Sources []Source `bson:"sources,omitempty" json:"sources,omitempty"`
+}
+
+// Source is a graph node an assistant reply drew on.
+type Source struct {
+	ID   string `bson:"id" json:"id"`
+	Type string `bson:"type" json:"type"`
+	Name string `bson:"name" json:"name"`
+	Link string `bson:"link,omitempty" json:"link,omitempty"` // e.g. a project's demo or GitHub URL
+}
+
+// HistoryQuery selects a window of a user's messages. Before and After are
+// IDs of messages the window lies strictly between; Limit caps its size.
+// Without After the window ends at the newest message it can reach, with
+// After it starts at the oldest one.
+type HistoryQuery struct {
+	Before string
+	After  string
+	Limit  int
+}
+
+// ConversationQuery selects a page of a conversation's messages, counting
+// back from the newest: Offset skips the newest messages and Limit caps the
+// page, with zero taking every message that's left.
+type ConversationQuery struct {
+	Offset int
+	Limit  int
+}
+
+// HistoryPage is a window of a user's messages, oldest first. HasMore is set
+// when more messages lie beyond it in the direction it was taken.
+type HistoryPage struct {
+	Messages []ChatMessage `json:"messages"`
+	HasMore  bool          `json:"hasMore"`
 }
 
 var client *mongo.Client
-var collection *mongo.Collection
+var database *mongo.Database
 
-// InitMongo connects to MongoDB using env variables and sets up the collection
+// InitMongo connects to MongoDB using env variables. Chat collections are
+// per tenant, see chatCollection. Calling it again keeps the open connection.
 func InitMongo() {
+	if client != nil {
+		return
+	}
 
 	uri := config.GetMongoURI()
 	dbName := config.GetMongoDB()
-	collName := config.GetMongoCollection()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -37,38 +88,124 @@ func InitMongo() {
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
 	}
-	collection = client.Database(dbName).Collection(collName)
+	database = client.Database(dbName)
 	log.Println("✅ Connected to MongoDB")
 }
 
-// StoreMessage saves a chat message in MongoDB
-func StoreMessage(userID string, msg ChatMessage) error {
+// chatCollection returns the chat history collection of the tenant ctx is scoped to.
+func chatCollection(ctx context.Context) *mongo.Collection {
+	return database.Collection(tenant.FromContext(ctx).ChatCollection)
+}
+
+// EnsureChatIndexes creates the indexes chat history lookups rely on in the
+// collections of the tenant ctx is scoped to.
+func EnsureChatIndexes(ctx context.Context) error {
+	_, err := chatCollection(ctx).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat history index: %w", err)
+	}
+	_, err = conversationCollection(ctx).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create conversation index: %w", err)
+	}
+	return nil
+}
+
+// StoreMessage saves a chat message in MongoDB and returns its hex ID. A
+// message in a conversation also counts towards it.
+func StoreMessage(ctx context.Context, userID string, msg ChatMessage) (string, error) {
 	msg.UserID = userID
 	msg.Timestamp = time.Now()
-	_, err := collection.InsertOne(context.Background(), msg)
-	return err
+	if msg.ID.IsZero() {
+		msg.ID = primitive.NewObjectID()
+	}
+	if _, err := chatCollection(ctx).InsertOne(ctx, msg); err != nil {
+		return "", err
+	}
+	if msg.ConversationID != "" {
+		if err := countConversationMessage(ctx, userID, msg.ConversationID, msg.Timestamp); err != nil {
+			return "", err
+		}
+	}
+	return msg.ID.Hex(), nil
 }
 
 // GetMessages retrieves all messages for a user
-func GetMessages(userID string) ([]ChatMessage, error) {
-	var messages []ChatMessage
+func GetMessages(ctx context.Context, userID string) ([]ChatMessage, error) {
+	return findMessages(ctx, bson.M{"user_id": userID}, byTimestamp(1))
+}
+
+// GetConversationMessages retrieves the page q selects of one of a user's
+// conversations, oldest first; an empty conversationID selects the messages
+// outside any.
+func GetConversationMessages(ctx context.Context, userID, conversationID string, q ConversationQuery) ([]ChatMessage, error) {
+	filter := bson.M{"user_id": userID, "conversation_id": conversationFilter(conversationID)}
+	messages, err := findMessages(ctx, filter, byTimestamp(-1).SetSkip(int64(q.Offset)).SetLimit(int64(q.Limit)))
+	slices.Reverse(messages)
+	return messages, err
+}
+
+// CountConversationMessages counts the messages of one of a user's
+// conversations; an empty conversationID counts the messages outside any.
+func CountConversationMessages(ctx context.Context, userID, conversationID string) (int, error) {
+	count, err := chatCollection(ctx).CountDocuments(ctx, bson.M{"user_id": userID, "conversation_id": conversationFilter(conversationID)})
+	return int(count), err
+}
 
+// GetHistory returns the window of a user's messages selected by q. Cursors
+// that aren't IDs of the user's messages fail with ErrNotFound.
+func GetHistory(ctx context.Context, userID string, q HistoryQuery) (HistoryPage, error) {
+	var bounds bson.A
+	for _, cursor := range []struct{ id, op string }{{q.Before, "$lt"}, {q.After, "$gt"}} {
+		if cursor.id == "" {
+			continue
+		}
+		bound, err := cursorBound(ctx, userID, cursor.id, cursor.op)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		bounds = append(bounds, bound)
+	}
 	filter := bson.M{"user_id": userID}
-	findOptions := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	if len(bounds) > 0 {
+		filter["$and"] = bounds
+	}
+
+	// Walk away from the cursor the window is anchored to, one past the limit
+	// to learn whether more follow
+	forward := q.After != ""
+	direction := -1
+	if forward {
+		direction = 1
+	}
+	messages, err := findMessages(ctx, filter, byTimestamp(direction).SetLimit(int64(q.Limit)+1))
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	return newHistoryPage(messages, q.Limit, forward), nil
+}
+
+// findMessages returns the messages matching filter in the order findOptions sets.
+func findMessages(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]ChatMessage, error) {
+	var messages []ChatMessage
 
-	cursor, err := collection.Find(context.TODO(), filter, findOptions)
+	cursor, err := chatCollection(ctx).Find(ctx, filter, findOptions)
 	if err != nil {
 		log.Printf("[ERROR] Find() failed: %v", err)
 		return nil, fmt.Errorf("Find() failed: %w", err)
 	}
 	defer func() {
-		if err := cursor.Close(context.TODO()); err != nil {
+		if err := cursor.Close(ctx); err != nil {
 			log.Printf("[WARN] Failed to close cursor: %v", err)
 		}
 	}()
 
 	count := 0
-	for cursor.Next(context.TODO()) {
+	for cursor.Next(ctx) {
 		var msg ChatMessage
 		if err := cursor.Decode(&msg); err != nil {
 			log.Printf("[ERROR] Decode() failed: %v", err)
@@ -85,3 +222,87 @@ func GetMessages(userID string) ([]ChatMessage, error) {
 
 	return messages, nil
 }
+
+// EachMessage calls fn with every stored message of the tenant ctx is scoped
+// to, ordered by user and timestamp, stopping at the first error.
+func EachMessage(ctx context.Context, fn func(ChatMessage) error) error {
+	findOptions := options.Find().SetSort(bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: 1}})
+	cursor, err := chatCollection(ctx).Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return fmt.Errorf("Find() failed: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Printf("[WARN] Failed to close cursor: %v", err)
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var msg ChatMessage
+		if err := cursor.Decode(&msg); err != nil {
+			return fmt.Errorf("Decode() failed: %w", err)
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// byTimestamp sorts messages by timestamp, then ID, in the given direction.
+func byTimestamp(direction int) *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "timestamp", Value: direction}, {Key: "_id", Value: direction}})
+}
+
+// cursorBound matches the user's messages sorting before ($lt) or after ($gt)
+// the message with the given ID.
+func cursorBound(ctx context.Context, userID, messageID, op string) (bson.M, error) {
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, messageNotFound(messageID)
+	}
+	var cursor ChatMessage
+	err = chatCollection(ctx).FindOne(
+		ctx,
+		bson.M{"_id": id, "user_id": userID},
+		options.FindOne().SetProjection(bson.M{"timestamp": 1}),
+	).Decode(&cursor)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, messageNotFound(messageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"timestamp": bson.M{op: cursor.Timestamp}},
+		bson.M{"timestamp": cursor.Timestamp, "_id": bson.M{op: id}},
+	}}, nil
+}
+
+// newHistoryPage trims messages fetched up to one past limit, walking
+// forward or backward in time, into a page.
+func newHistoryPage(messages []ChatMessage, limit int, forward bool) HistoryPage {
+	page := HistoryPage{Messages: messages, HasMore: len(messages) > limit}
+	if page.HasMore {
+		page.Messages = messages[:limit]
+	}
+	if !forward {
+		slices.Reverse(page.Messages)
+	}
+	if page.Messages == nil {
+		page.Messages = []ChatMessage{}
+	}
+	return page
+}
+
+func messageNotFound(messageID string) error {
+	return fmt.Errorf("message %q %w", messageID, ErrNotFound)
+}
+
+// conversationFilter matches conversationID, or a missing one when it is empty.
+func conversationFilter(conversationID string) any {
+	if conversationID == "" {
+		return nil
+	}
+	return conversationID
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+var (
+	// ErrNotFound is returned when a write targets a node or relationship that doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when a create or rename would duplicate a node's key.
+	ErrExists = errors.New("already exists")
+)
+
+// GraphNode is a resume model that can be written to the graph. Nodes are
+// identified by their key property: id for dated entries, courses and the
+// Person, name for hobbies, skills and tags.
+type GraphNode interface {
+	Label() string
+	Key() string
+	Properties() map[string]any
+	Validate() error
+}
+
+// NodeRef points at a node by label and key.
+type NodeRef struct {
+	Label string `json:"label"`
+	Key   string `json:"key"`
+}
+
+// Relationship is an edge between two resume nodes.
+type Relationship struct {
+	Type string  `json:"type"`
+	From NodeRef `json:"from"`
+	To   NodeRef `json:"to"`
+}
+
+// keyProperties maps each writable label to the property that identifies it.
+var keyProperties = map[string]string{
+	"Project":        "id",
+	"WorkExperience": "id",
+	"Education":      "id",
+	"Course":         "id",
+	"Person":         "id",
+	"Hobby":          "name",
+	"Skill":          "name",
+	"Tag":            "name",
+}
+
+// relationshipRules lists, per relationship type, the labels it may start
+// from and the label it points to.
+var relationshipRules = map[string]struct {
+	From []string
+	To   string
+}{
+	"USES":      {From: []string{"Project"}, To: "Skill"},
+	"HAS_TAG":   {From: []string{"Project", "WorkExperience", "Education", "Hobby"}, To: "Tag"},
+	"INSPIRED":  {From: []string{"Hobby"}, To: "Project"},
+	"WORKED_ON": {From: []string{"Project"}, To: "WorkExperience"},
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// NODES
+// ─────────────────────────────────────────────────────────────────────────────
+
+// CreateNode validates and stores a new node, failing with ErrExists when a
+// node with the same key is already in the graph.
+func CreateNode(ctx context.Context, n GraphNode) error {
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		OPTIONAL MATCH (existing:%[1]s {%[2]s: $key})
+		WITH existing WHERE existing IS NULL
+		CREATE (n:%[1]s)
+		SET n = $props
+		RETURN count(n) AS created
+	`, n.Label(), keyProperties[n.Label()])
+
+	created, err := writeCount(ctx, query, map[string]any{"key": n.Key(), "props": n.Properties()}, "created")
+	if err != nil {
+		return err
+	}
+	if created == 0 {
+		return fmt.Errorf("%s %q %w", n.Label(), n.Key(), ErrExists)
+	}
+	return nil
+}
+
+// UpdateNode validates n and overwrites the model fields of the node stored
+// under key, leaving other properties (aliases, embeddings) alone. A different
+// key in n renames the node, unless that key is already taken.
+func UpdateNode(ctx context.Context, key string, n GraphNode) error {
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%[1]s {%[2]s: $key})
+		OPTIONAL MATCH (other:%[1]s {%[2]s: $newKey})
+		WITH n, other WHERE other IS NULL OR other = n
+		SET n += $props
+		RETURN count(n) AS updated
+	`, n.Label(), keyProperties[n.Label()])
+
+	updated, err := writeCount(ctx, query, map[string]any{"key": key, "newKey": n.Key(), "props": n.Properties()}, "updated")
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+	if exists, err := nodeExists(ctx, NodeRef{Label: n.Label(), Key: key}); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("%s %q %w", n.Label(), n.Key(), ErrExists)
+	}
+	return fmt.Errorf("%s %q %w", n.Label(), key, ErrNotFound)
+}
+
+// DeleteNode removes a node and every relationship attached to it.
+func DeleteNode(ctx context.Context, ref NodeRef) error {
+	keyProp, ok := keyProperties[ref.Label]
+	if !ok {
+		return fmt.Errorf("unknown node label %q", ref.Label)
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s {%s: $key})
+		DETACH DELETE n
+		RETURN count(n) AS deleted
+	`, ref.Label, keyProp)
+
+	deleted, err := writeCount(ctx, query, map[string]any{"key": ref.Key}, "deleted")
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%s %q %w", ref.Label, ref.Key, ErrNotFound)
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// RELATIONSHIPS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// CreateRelationship links two existing nodes. Linking them twice is a no-op.
+func CreateRelationship(ctx context.Context, rel Relationship) error {
+	if err := rel.Validate(); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		MATCH (a:%s {%s: $from}), (b:%s {%s: $to})
+		MERGE (a)-[r:%s]->(b)
+		RETURN count(r) AS linked
+	`, rel.From.Label, keyProperties[rel.From.Label], rel.To.Label, keyProperties[rel.To.Label], rel.Type)
+
+	linked, err := writeCount(ctx, query, map[string]any{"from": rel.From.Key, "to": rel.To.Key}, "linked")
+	if err != nil {
+		return err
+	}
+	if linked == 0 {
+		return fmt.Errorf("%s %q or %s %q %w", rel.From.Label, rel.From.Key, rel.To.Label, rel.To.Key, ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteRelationship unlinks two nodes.
+func DeleteRelationship(ctx context.Context, rel Relationship) error {
+	if err := rel.Validate(); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		MATCH (:%s {%s: $from})-[r:%s]->(:%s {%s: $to})
+		DELETE r
+		RETURN count(r) AS unlinked
+	`, rel.From.Label, keyProperties[rel.From.Label], rel.Type, rel.To.Label, keyProperties[rel.To.Label])
+
+	unlinked, err := writeCount(ctx, query, map[string]any{"from": rel.From.Key, "to": rel.To.Key}, "unlinked")
+	if err != nil {
+		return err
+	}
+	if unlinked == 0 {
+		return fmt.Errorf("%s relationship %w", rel.Type, ErrNotFound)
+	}
+	return nil
+}
+
+// Validate checks the relationship type and that it connects the labels it allows.
+func (r Relationship) Validate() error {
+	rule, ok := relationshipRules[r.Type]
+	if !ok {
+		return validationError("type", "must be one of USES, HAS_TAG, INSPIRED, WORKED_ON")
+	}
+	if !slices.Contains(rule.From, r.From.Label) {
+		return validationError("from.label", fmt.Sprintf("%s can't start from %q", r.Type, r.From.Label))
+	}
+	if r.To.Label != rule.To {
+		return validationError("to.label", fmt.Sprintf("%s must point to %s", r.Type, rule.To))
+	}
+	if r.From.Key == "" || r.To.Key == "" {
+		return validationError("key", "both ends need a key")
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// writeCount runs a write query that returns a single count column.
+func writeCount(ctx context.Context, query string, params map[string]any, column string) (int64, error) {
+	result, err := withWriteSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		count, _ := record.Get(column)
+		return count, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.(int64), nil
+}
+
+// nodeExists reports whether a node with the given label and key is stored.
+func nodeExists(ctx context.Context, ref NodeRef) (bool, error) {
+	query := fmt.Sprintf(`MATCH (n:%s {%s: $key}) RETURN count(n) > 0 AS found`, ref.Label, keyProperties[ref.Label])
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, map[string]any{"key": ref.Key})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		found, _ := record.Get("found")
+		return found, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
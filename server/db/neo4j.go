@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"go-ai/config"
+	"go-ai/tenant"
 	"log"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -20,3 +22,11 @@ func InitNeo4j() {
 	Neo4jDriver = driver
 	log.Println("✅ Connected to Neo4j")
 }
+
+// sessionConfig opens sessions on the Neo4j database of the tenant ctx is scoped to.
+func sessionConfig(ctx context.Context, mode neo4j.AccessMode) neo4j.SessionConfig {
+	return neo4j.SessionConfig{
+		AccessMode:   mode,
+		DatabaseName: tenant.FromContext(ctx).Neo4jDatabase,
+	}
+}
@@ -3,19 +3,24 @@ package db
 import (
 	"context"
 	"fmt"
+	"go-ai/tenant"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-func GetPerson() (*Person, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+// GetPerson returns the Person node of the tenant ctx is scoped to: the one
+// with the tenant's personId, or the only Person when none is configured.
+func GetPerson(ctx context.Context) (*Person, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	query := `MATCH (p:Person) RETURN p LIMIT 1`
-	result, err := session.Run(ctx, query, nil)
+	var params map[string]any
+	if personID := tenant.FromContext(ctx).PersonID; personID != "" {
+		query = `MATCH (p:Person {id: $id}) RETURN p`
+		params = map[string]any{"id": personID}
+	}
+	result, err := session.Run(ctx, query, params)
 	if err != nil {
 		return nil, err
 	}
@@ -13,19 +13,19 @@ import (
 
 // SearchProjectsByName returns projects where the name matches input (case-insensitive).
 // Previously: FindProjectsByName
-func SearchProjectsByName(name string) ([]Project, error) {
+func SearchProjectsByName(ctx context.Context, name string) ([]Project, error) {
 	query := `
 		MATCH (p:Project)
 		WHERE toLower(p.name) CONTAINS toLower($name)
 		RETURN p
 	`
 	params := map[string]interface{}{"name": name}
-	return queryProjects(query, params)
+	return queryProjects(ctx, query, params)
 }
 
 // GetAllProjectsSorted returns all projects sorted by start date.
 // Previously: GetAllProjects
-func GetAllProjectsSorted() ([]Project, error) {
+func GetAllProjectsSorted(ctx context.Context) ([]Project, error) {
 	query := `
 		MATCH (p:Project)
 		RETURN 
@@ -42,13 +42,13 @@ func GetAllProjectsSorted() ([]Project, error) {
 			p.github AS github
 		ORDER BY p.startDate DESC
 	`
-	return runProjectResultQuery(query, nil)
+	return runProjectResultQuery(ctx, query, nil)
 }
 
 // ListProjectNames returns all project names only.
 // Previously: GetAllProjectNames
-func ListProjectNames() ([]string, error) {
-	projects, err := GetAllProjectsSorted()
+func ListProjectNames(ctx context.Context) ([]string, error) {
+	projects, err := GetAllProjectsSorted(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +60,7 @@ func ListProjectNames() ([]string, error) {
 }
 
 // FindProjectsByTag returns projects associated with a specific tag.
-func FindProjectsByTag(tag string) ([]Project, error) {
+func FindProjectsByTag(ctx context.Context, tag string) ([]Project, error) {
 	query := `
 		MATCH (p:Project)-[:HAS_TAG]->(t:Tag {name: $tag})
 		RETURN 
@@ -77,11 +77,11 @@ func FindProjectsByTag(tag string) ([]Project, error) {
 			p.github AS github
 		ORDER BY p.startDate DESC
 	`
-	return runProjectResultQuery(query, map[string]any{"tag": tag})
+	return runProjectResultQuery(ctx, query, map[string]any{"tag": tag})
 }
 
 // FindProjectsBySkill returns projects that use a specific skill.
-func FindProjectsBySkill(skill string) ([]Project, error) {
+func FindProjectsBySkill(ctx context.Context, skill string) ([]Project, error) {
 	query := `
 		MATCH (p:Project)-[:USES]->(s:Skill {name: $skill})
 		RETURN 
@@ -98,12 +98,12 @@ func FindProjectsBySkill(skill string) ([]Project, error) {
 			p.github AS github
 		ORDER BY p.startDate DESC
 	`
-	return runProjectResultQuery(query, map[string]any{"skill": skill})
+	return runProjectResultQuery(ctx, query, map[string]any{"skill": skill})
 }
 
 // FindProjectsConnectedToHobby returns projects linked to a specific hobby.
 // Previously: FindProjectsConnectedToHobby
-func FindProjectsByHobby(hobbyName string) ([]Project, error) {
+func FindProjectsByHobby(ctx context.Context, hobbyName string) ([]Project, error) {
 	query := `
 		MATCH (h:Hobby {name: $hobbyName})-[:INSPIRED]->(p:Project)
 		RETURN 
@@ -120,17 +120,15 @@ func FindProjectsByHobby(hobbyName string) ([]Project, error) {
 			p.github AS github
 		ORDER BY p.startDate DESC
 	`
-	return runProjectResultQuery(query, map[string]any{"hobbyName": hobbyName})
+	return runProjectResultQuery(ctx, query, map[string]any{"hobbyName": hobbyName})
 }
 
 // GetProjectDetails returns a single project with its connected skills, tags, and work experience.
-func GetProjectDetails(projectID string) (ProjectDetails, error) {
-	session := Neo4jDriver.NewSession(context.Background(), neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-	defer session.Close(context.Background())
+func GetProjectDetails(ctx context.Context, projectID string) (ProjectDetails, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
+	defer session.Close(ctx)
 
-	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (p:Project {id: $projectID})
 			OPTIONAL MATCH (p)-[:USES]->(s:Skill)
@@ -143,12 +141,12 @@ func GetProjectDetails(projectID string) (ProjectDetails, error) {
 				w
 		`
 		params := map[string]any{"projectID": projectID}
-		res, err := tx.Run(context.Background(), query, params)
+		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
 		}
 
-		if res.Next(context.Background()) {
+		if res.Next(ctx) {
 			record := res.Record()
 			pNode, _ := record.Get("p")
 			skillNodes, _ := record.Get("skills")
@@ -179,11 +177,8 @@ func GetProjectDetails(projectID string) (ProjectDetails, error) {
 
 // queryProjects is a fallback lightweight query that returns minimal Project data.
 // Previously: runProjectQuery
-func queryProjects(cypher string, params map[string]interface{}) ([]Project, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func queryProjects(ctx context.Context, cypher string, params map[string]interface{}) ([]Project, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, cypher, params)
@@ -218,20 +213,18 @@ func queryProjects(cypher string, params map[string]interface{}) ([]Project, err
 }
 
 // runProjectResultQuery is the full record-based Cypher processor
-func runProjectResultQuery(query string, params map[string]interface{}) ([]Project, error) {
-	session := Neo4jDriver.NewSession(context.Background(), neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-	defer session.Close(context.Background())
+func runProjectResultQuery(ctx context.Context, query string, params map[string]interface{}) ([]Project, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
+	defer session.Close(ctx)
 
-	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(context.Background(), query, params)
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
 		}
 
 		var projects []Project
-		for res.Next(context.Background()) {
+		for res.Next(ctx) {
 			record := res.Record()
 			project := Project{
 				ID:            asString(record, "id"),
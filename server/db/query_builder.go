@@ -0,0 +1,168 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// cypherQuery is a query string with its parameters.
+type cypherQuery struct {
+	Text   string
+	Params map[string]any
+}
+
+// filterTarget describes how FilterClauses apply to one node type. Predicates
+// are keyed by FilterClause.On and reference the filter value as $value.
+type filterTarget struct {
+	Label      string
+	Var        string
+	Predicates map[string]string
+	Return     string
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// FILTER TARGETS
+// ─────────────────────────────────────────────────────────────────────────────
+
+var projectFilterTarget = filterTarget{
+	Label: "Project",
+	Var:   "p",
+	Predicates: map[string]string{
+		"Tag":         `EXISTS { MATCH (p)-[:HAS_TAG]->(t:Tag) WHERE toLower(t.name) = toLower($value) }`,
+		"Skill":       `EXISTS { MATCH (p)-[:USES]->(s:Skill) WHERE toLower(s.name) = toLower($value) }`,
+		"Hobby":       `EXISTS { MATCH (h:Hobby)-[:INSPIRED]->(p) WHERE toLower(h.name) = toLower($value) }`,
+		"Name":        `toLower(p.name) CONTAINS toLower($value)`,
+		"Company":     `EXISTS { MATCH (p)-[:WORKED_ON]->(w:WorkExperience) WHERE toLower(w.company) CONTAINS toLower($value) }`,
+		"Institution": `toLower(p.institution) CONTAINS toLower($value)`,
+	},
+	Return: `
+		RETURN
+			p.id AS id,
+			p.name AS name,
+			p.description AS description,
+			p.institution AS institution,
+			p.image AS image,
+			p.featured AS featured,
+			p.contributions AS contributions,
+			p.startDate AS startDate,
+			p.endDate AS endDate,
+			p.demo AS demo,
+			p.github AS github
+		ORDER BY p.startDate DESC
+	`,
+}
+
+var workExperienceFilterTarget = filterTarget{
+	Label: "WorkExperience",
+	Var:   "w",
+	Predicates: map[string]string{
+		"Tag":     `EXISTS { MATCH (w)-[:HAS_TAG]->(t:Tag) WHERE toLower(t.name) = toLower($value) }`,
+		"Skill":   `EXISTS { MATCH (p:Project)-[:WORKED_ON]->(w), (p)-[:USES]->(s:Skill) WHERE toLower(s.name) = toLower($value) }`,
+		"Company": `toLower(w.company) CONTAINS toLower($value)`,
+		"Name":    `(toLower(w.company) CONTAINS toLower($value) OR toLower(w.title) CONTAINS toLower($value))`,
+	},
+	Return: `
+		RETURN w
+		ORDER BY w.startDate
+	`,
+}
+
+var educationFilterTarget = filterTarget{
+	Label: "Education",
+	Var:   "e",
+	Predicates: map[string]string{
+		"Tag":         `EXISTS { MATCH (e)-[:HAS_TAG]->(t:Tag) WHERE toLower(t.name) = toLower($value) }`,
+		"Institution": `toLower(e.institution) CONTAINS toLower($value)`,
+		"Field":       `toLower(e.field) CONTAINS toLower($value)`,
+		"Name":        `(toLower(e.degree) CONTAINS toLower($value) OR toLower(e.institution) CONTAINS toLower($value))`,
+	},
+	Return: `
+		RETURN
+			e.id AS id,
+			e.summary AS summary,
+			e.institution AS institution,
+			e.field AS field,
+			e.degree AS degree,
+			e.level AS level,
+			e.startDate AS startDate,
+			e.endDate AS endDate,
+			COALESCE(e.leadership, []) AS leadership
+		ORDER BY e.startDate
+	`,
+}
+
+var hobbyFilterTarget = filterTarget{
+	Label: "Hobby",
+	Var:   "h",
+	Predicates: map[string]string{
+		"Tag":  `EXISTS { MATCH (h)-[:HAS_TAG]->(t:Tag) WHERE toLower(t.name) = toLower($value) }`,
+		"Name": `toLower(h.name) CONTAINS toLower($value)`,
+	},
+	Return: `
+		RETURN h
+		ORDER BY h.name
+	`,
+}
+
+var skillFilterTarget = filterTarget{
+	Label: "Skill",
+	Var:   "s",
+	Predicates: map[string]string{
+		"Tag":  `EXISTS { MATCH (s)<-[:USES]-(:Project)-[:HAS_TAG]->(t:Tag) WHERE toLower(t.name) = toLower($value) }`,
+		"Name": `toLower(s.name) CONTAINS toLower($value)`,
+	},
+	Return: `
+		RETURN s
+		ORDER BY s.name
+	`,
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// BUILDER
+// ─────────────────────────────────────────────────────────────────────────────
+
+// buildFilterQuery turns every FilterClause into one parameterised query:
+// all "and" clauses must hold, at least one "or" clause must hold (if any),
+// and no "not" clause may hold. Filters the node type can't apply are skipped.
+func buildFilterQuery(target filterTarget, filters []FilterClause) cypherQuery {
+	params := map[string]any{}
+	var and, or, not []string
+
+	for i, f := range filters {
+		tmpl, ok := target.Predicates[f.On]
+		if !ok {
+			log.Printf("⚠️ Ignoring unsupported %s filter: %+v\n", target.Label, f)
+			continue
+		}
+
+		param := fmt.Sprintf("f%d", i)
+		params[param] = f.Value
+		predicate := strings.ReplaceAll(tmpl, "$value", "$"+param)
+
+		switch f.op() {
+		case FilterOr:
+			or = append(or, predicate)
+		case FilterNot:
+			not = append(not, "NOT "+predicate)
+		default:
+			and = append(and, predicate)
+		}
+	}
+
+	var conditions []string
+	conditions = append(conditions, and...)
+	conditions = append(conditions, not...)
+	if len(or) > 0 {
+		conditions = append(conditions, "("+strings.Join(or, " OR ")+")")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n\t\tMATCH (%s:%s)\n", target.Var, target.Label))
+	if len(conditions) > 0 {
+		b.WriteString("\t\tWHERE " + strings.Join(conditions, "\n\t\t  AND ") + "\n")
+	}
+	b.WriteString(target.Return)
+
+	return cypherQuery{Text: b.String(), Params: params}
+}
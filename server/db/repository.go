@@ -0,0 +1,181 @@
+package db
+
+import "context"
+
+// ResumeRepository is the read side of the resume graph the chat pipeline
+// depends on. Neo4jRepository serves it from Neo4j; MemoryRepository serves
+// it from an export document, for local runs and tests.
+type ResumeRepository interface {
+	GetPerson(ctx context.Context) (*Person, error)
+	Schema(ctx context.Context) GraphSchema
+
+	FindProjectsWithFilters(ctx context.Context, filters []FilterClause) ([]Project, error)
+	FindWorkExperienceWithFilters(ctx context.Context, filters []FilterClause) ([]WorkExperience, error)
+	FindEducationWithFilters(ctx context.Context, filters []FilterClause) ([]Education, error)
+	FindHobbiesWithFilters(ctx context.Context, filters []FilterClause) ([]Hobby, error)
+	FindSkillsWithFilters(ctx context.Context, filters []FilterClause) ([]Skill, error)
+
+	ListProjectNames(ctx context.Context) ([]string, error)
+	ListWorkExperienceCompanies(ctx context.Context) ([]string, error)
+	GetAllSkillsSorted(ctx context.Context) ([]Skill, error)
+	GetAllTagsSorted(ctx context.Context) ([]Tag, error)
+	GetAllHobbies(ctx context.Context) ([]Hobby, error)
+	ListEntityNames(ctx context.Context, label string) ([]EntityName, error)
+
+	FullTextSearch(ctx context.Context, text string, k int) ([]SimilarNode, error)
+	SearchSimilarNodes(ctx context.Context, vector []float64, k int) ([]SimilarNode, error)
+}
+
+// ChatStore keeps each user's conversations, chat history and rolling
+// summaries. MongoChatStore keeps them in MongoDB, BoltChatStore in a local
+// file and MemoryChatStore in memory. Conversation and history cursor lookups
+// fail with ErrNotFound for conversations and messages of other users.
+type ChatStore interface {
+	StoreMessage(ctx context.Context, userID string, msg ChatMessage) (string, error)
+	GetMessages(ctx context.Context, userID string) ([]ChatMessage, error)
+	GetConversationMessages(ctx context.Context, userID, conversationID string, q ConversationQuery) ([]ChatMessage, error)
+	CountConversationMessages(ctx context.Context, userID, conversationID string) (int, error)
+	GetHistory(ctx context.Context, userID string, q HistoryQuery) (HistoryPage, error)
+	GetSummary(ctx context.Context, userID, conversationID string) (*ConversationSummary, error)
+	StoreSummary(ctx context.Context, summary ConversationSummary) error
+
+	CreateConversation(ctx context.Context, userID, title string) (Conversation, error)
+	ListConversations(ctx context.Context, userID string) ([]Conversation, error)
+	GetConversation(ctx context.Context, userID, conversationID string) (Conversation, error)
+	RenameConversation(ctx context.Context, userID, conversationID, title string) (Conversation, error)
+	DeleteConversation(ctx context.Context, userID, conversationID string) error
+
+	DeleteUserHistory(ctx context.Context, userID string) error
+}
+
+var (
+	_ ResumeRepository = Neo4jRepository{}
+	_ ChatStore        = MongoChatStore{}
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// NEO4J
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Neo4jRepository serves the resume graph from the Neo4j connection opened by
+// InitNeo4j.
+type Neo4jRepository struct{}
+
+func (Neo4jRepository) GetPerson(ctx context.Context) (*Person, error) {
+	return GetPerson(ctx)
+}
+
+func (Neo4jRepository) Schema(ctx context.Context) GraphSchema {
+	return SchemaFor(ctx)
+}
+
+func (Neo4jRepository) FindProjectsWithFilters(ctx context.Context, filters []FilterClause) ([]Project, error) {
+	return FindProjectsWithFilters(ctx, filters)
+}
+
+func (Neo4jRepository) FindWorkExperienceWithFilters(ctx context.Context, filters []FilterClause) ([]WorkExperience, error) {
+	return FindWorkExperienceWithFilters(ctx, filters)
+}
+
+func (Neo4jRepository) FindEducationWithFilters(ctx context.Context, filters []FilterClause) ([]Education, error) {
+	return FindEducationWithFilters(ctx, filters)
+}
+
+func (Neo4jRepository) FindHobbiesWithFilters(ctx context.Context, filters []FilterClause) ([]Hobby, error) {
+	return FindHobbiesWithFilters(ctx, filters)
+}
+
+func (Neo4jRepository) FindSkillsWithFilters(ctx context.Context, filters []FilterClause) ([]Skill, error) {
+	return FindSkillsWithFilters(ctx, filters)
+}
+
+func (Neo4jRepository) ListProjectNames(ctx context.Context) ([]string, error) {
+	return ListProjectNames(ctx)
+}
+
+func (Neo4jRepository) ListWorkExperienceCompanies(ctx context.Context) ([]string, error) {
+	return ListWorkExperienceCompanies(ctx)
+}
+
+func (Neo4jRepository) GetAllSkillsSorted(ctx context.Context) ([]Skill, error) {
+	return GetAllSkillsSorted(ctx)
+}
+
+func (Neo4jRepository) GetAllTagsSorted(ctx context.Context) ([]Tag, error) {
+	return GetAllTagsSorted(ctx)
+}
+
+func (Neo4jRepository) GetAllHobbies(ctx context.Context) ([]Hobby, error) {
+	return GetAllHobbies(ctx)
+}
+
+func (Neo4jRepository) ListEntityNames(ctx context.Context, label string) ([]EntityName, error) {
+	return ListEntityNames(ctx, label)
+}
+
+func (Neo4jRepository) FullTextSearch(ctx context.Context, text string, k int) ([]SimilarNode, error) {
+	return FullTextSearch(ctx, text, k)
+}
+
+func (Neo4jRepository) SearchSimilarNodes(ctx context.Context, vector []float64, k int) ([]SimilarNode, error) {
+	return SearchSimilarNodes(ctx, vector, k)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// MONGO
+// ─────────────────────────────────────────────────────────────────────────────
+
+// MongoChatStore keeps chat history in the MongoDB database opened by InitMongo.
+type MongoChatStore struct{}
+
+func (MongoChatStore) StoreMessage(ctx context.Context, userID string, msg ChatMessage) (string, error) {
+	return StoreMessage(ctx, userID, msg)
+}
+
+func (MongoChatStore) GetMessages(ctx context.Context, userID string) ([]ChatMessage, error) {
+	return GetMessages(ctx, userID)
+}
+
+func (MongoChatStore) GetConversationMessages(ctx context.Context, userID, conversationID string, q ConversationQuery) ([]ChatMessage, error) {
+	return GetConversationMessages(ctx, userID, conversationID, q)
+}
+
+func (MongoChatStore) CountConversationMessages(ctx context.Context, userID, conversationID string) (int, error) {
+	return CountConversationMessages(ctx, userID, conversationID)
+}
+
+func (MongoChatStore) GetHistory(ctx context.Context, userID string, q HistoryQuery) (HistoryPage, error) {
+	return GetHistory(ctx, userID, q)
+}
+
+func (MongoChatStore) GetSummary(ctx context.Context, userID, conversationID string) (*ConversationSummary, error) {
+	return GetSummary(ctx, userID, conversationID)
+}
+
+func (MongoChatStore) StoreSummary(ctx context.Context, summary ConversationSummary) error {
+	return StoreSummary(ctx, summary)
+}
+
+func (MongoChatStore) CreateConversation(ctx context.Context, userID, title string) (Conversation, error) {
+	return CreateConversation(ctx, userID, title)
+}
+
+func (MongoChatStore) ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	return ListConversations(ctx, userID)
+}
+
+func (MongoChatStore) GetConversation(ctx context.Context, userID, conversationID string) (Conversation, error) {
+	return GetConversation(ctx, userID, conversationID)
+}
+
+func (MongoChatStore) RenameConversation(ctx context.Context, userID, conversationID, title string) (Conversation, error) {
+	return RenameConversation(ctx, userID, conversationID, title)
+}
+
+func (MongoChatStore) DeleteConversation(ctx context.Context, userID, conversationID string) error {
+	return DeleteConversation(ctx, userID, conversationID)
+}
+
+func (MongoChatStore) DeleteUserHistory(ctx context.Context, userID string) error {
+	return DeleteUserHistory(ctx, userID)
+}
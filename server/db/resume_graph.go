@@ -0,0 +1,45 @@
+package db
+
+// ResumeGraph is a whole resume as graph nodes and the relationships between
+// them. Importers build one and reconcile it with the stored graph.
+type ResumeGraph struct {
+	Person         *Person          `json:"person,omitempty"`
+	Projects       []Project        `json:"projects"`
+	WorkExperience []WorkExperience `json:"workExperience"`
+	Education      []Education      `json:"education"`
+	Courses        []Course         `json:"courses"`
+	Hobbies        []Hobby          `json:"hobbies"`
+	Skills         []Skill          `json:"skills"`
+	Tags           []Tag            `json:"tags"`
+	Relationships  []Relationship   `json:"relationships"`
+}
+
+// Nodes returns every node in the graph, Person first.
+func (g ResumeGraph) Nodes() []GraphNode {
+	var nodes []GraphNode
+	if g.Person != nil {
+		nodes = append(nodes, *g.Person)
+	}
+	for _, n := range g.Projects {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.WorkExperience {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.Education {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.Courses {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.Hobbies {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.Skills {
+		nodes = append(nodes, n)
+	}
+	for _, n := range g.Tags {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
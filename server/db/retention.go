@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDB error codes ensureTTLIndex tolerates.
+const (
+	mongoNamespaceNotFound    = 26
+	mongoIndexNotFound        = 27
+	mongoIndexOptionsConflict = 85
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PUBLIC FUNCTIONS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// EnsureChatRetention makes MongoDB expire the chat history of the tenant ctx
+// is scoped to once it is older than retention: messages by their timestamp,
+// conversations and summaries by when they were last updated. A retention of
+// zero keeps history forever and drops indexes left from an earlier period.
+func EnsureChatRetention(ctx context.Context, retention time.Duration) error {
+	for _, ttl := range []struct {
+		collection *mongo.Collection
+		field      string
+	}{
+		{chatCollection(ctx), "timestamp"},
+		{conversationCollection(ctx), "updated_at"},
+		{summaryCollection(ctx), "updated_at"},
+	} {
+		if err := ensureTTLIndex(ctx, ttl.collection, ttl.field, retention); err != nil {
+			return fmt.Errorf("failed to apply chat retention to %s: %w", ttl.collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// DeleteUserHistory removes every message, conversation and summary of a user.
+func DeleteUserHistory(ctx context.Context, userID string) error {
+	filter := bson.M{"user_id": userID}
+	for _, collection := range []*mongo.Collection{chatCollection(ctx), conversationCollection(ctx), summaryCollection(ctx)} {
+		if _, err := collection.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ensureTTLIndex creates, updates or drops the TTL index on field so
+// documents expire retention after it.
+func ensureTTLIndex(ctx context.Context, collection *mongo.Collection, field string, retention time.Duration) error {
+	name := field + "_ttl"
+	var cmdErr mongo.CommandError
+
+	if retention <= 0 {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		if errors.As(err, &cmdErr) && (cmdErr.Code == mongoIndexNotFound || cmdErr.Code == mongoNamespaceNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	seconds := int32(retention.Seconds())
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetName(name).SetExpireAfterSeconds(seconds),
+	})
+	if errors.As(err, &cmdErr) && cmdErr.Code == mongoIndexOptionsConflict {
+		// The index exists with an earlier period
+		return collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collection.Name()},
+			{Key: "index", Value: bson.M{"name": name, "expireAfterSeconds": seconds}},
+		}).Err()
+	}
+	return err
+}
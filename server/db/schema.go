@@ -3,6 +3,11 @@ package db
 import (
 	"context"
 	"fmt"
+	"go-ai/config"
+	"go-ai/tenant"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
@@ -25,23 +30,51 @@ type GraphSchema struct {
 	Relationships []string
 }
 
-var CachedSchema GraphSchema
+// cachedSchema is a tenant's graph schema and when it was loaded.
+type cachedSchema struct {
+	schema   GraphSchema
+	loadedAt time.Time
+}
 
-func LoadGraphSchemaOnce() error {
-	session := Neo4jDriver.NewSession(context.Background(), neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+// schemas caches each tenant's cachedSchema by tenant ID.
+var schemas sync.Map
+
+// SchemaFor returns the cached schema of the tenant ctx is scoped to, loading
+// it on first use and again once it is older than PLANNER_CACHE_TTL_SECONDS.
+// When reloading fails the previous schema is kept; a schema that never
+// loaded is returned empty, which the planner treats as "accept every
+// queryable label".
+func SchemaFor(ctx context.Context) GraphSchema {
+	cached, ok := schemas.Load(tenant.FromContext(ctx).ID)
+	if ok && time.Since(cached.(cachedSchema).loadedAt) < config.GetPlannerCacheTTL() {
+		return cached.(cachedSchema).schema
+	}
+	if err := LoadGraphSchemaOnce(ctx); err != nil {
+		log.Printf("⚠️ Failed to load graph schema: %v", err)
+		if ok {
+			return cached.(cachedSchema).schema
+		}
+		return GraphSchema{}
+	}
+	cached, _ = schemas.Load(tenant.FromContext(ctx).ID)
+	return cached.(cachedSchema).schema
+}
+
+// LoadGraphSchemaOnce loads and caches the schema of the tenant ctx is scoped
+// to. Calling it again refreshes the cache, e.g. after content changed.
+func LoadGraphSchemaOnce(ctx context.Context) error {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 
-	defer session.Close(context.Background())
+	defer session.Close(ctx)
 
 	// Load Node Labels
-	nodeLabels, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(context.Background(), GetAllNodeLabels, nil)
+	nodeLabels, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, GetAllNodeLabels, nil)
 		if err != nil {
 			return nil, err
 		}
 		var labels []string
-		for res.Next(context.Background()) {
+		for res.Next(ctx) {
 			label, _ := res.Record().Get("label")
 			labels = append(labels, label.(string))
 		}
@@ -52,13 +85,13 @@ func LoadGraphSchemaOnce() error {
 	}
 
 	// Load Relationships
-	relationships, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(context.Background(), GetAllSchemaRelationships, nil)
+	relationships, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, GetAllSchemaRelationships, nil)
 		if err != nil {
 			return nil, err
 		}
 		var rels []string
-		for res.Next(context.Background()) {
+		for res.Next(ctx) {
 			from, _ := res.Record().Get("from")
 			rel, _ := res.Record().Get("rel")
 			to, _ := res.Record().Get("to")
@@ -70,9 +103,12 @@ func LoadGraphSchemaOnce() error {
 		return fmt.Errorf("failed to load schema relationships: %w", err)
 	}
 
-	CachedSchema = GraphSchema{
-		NodeLabels:    nodeLabels.([]string),
-		Relationships: relationships.([]string),
-	}
+	schemas.Store(tenant.FromContext(ctx).ID, cachedSchema{
+		schema: GraphSchema{
+			NodeLabels:    nodeLabels.([]string),
+			Relationships: relationships.([]string),
+		},
+		loadedAt: time.Now(),
+	})
 	return nil
 }
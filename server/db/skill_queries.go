@@ -11,26 +11,23 @@ import (
 // ─────────────────────────────────────────────────────────────────────────────
 
 // GetAllSkillsSorted returns all Skill nodes ordered by name.
-func GetAllSkillsSorted() ([]Skill, error) {
+func GetAllSkillsSorted(ctx context.Context) ([]Skill, error) {
 	query := `
 		MATCH (s:Skill)
 		RETURN s
 		ORDER BY s.name
 	`
-	return querySkills(query, nil)
+	return querySkills(ctx, query, nil)
 }
 
 // SearchSkillsByTag returns skills associated with a specific project tag.
-func SearchSkillsByTag(tag string) ([]Skill, error) {
+func SearchSkillsByTag(ctx context.Context, tag string) ([]Skill, error) {
 	query := `
 		MATCH (s:Skill)<-[:USES]-(p:Project)-[:HAS_TAG]->(t:Tag {name: $tag})
 		RETURN DISTINCT s.name AS name
 		ORDER BY name
 	`
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, query, map[string]any{"tag": tag})
@@ -54,14 +51,14 @@ func SearchSkillsByTag(tag string) ([]Skill, error) {
 }
 
 // SearchSkillsByName performs a case-insensitive fuzzy match on skill name.
-func SearchSkillsByName(name string) ([]Skill, error) {
+func SearchSkillsByName(ctx context.Context, name string) ([]Skill, error) {
 	query := `
 		MATCH (s:Skill)
 		WHERE toLower(s.name) CONTAINS toLower($name)
 		RETURN s
 	`
 	params := map[string]interface{}{"name": name}
-	return querySkills(query, params)
+	return querySkills(ctx, query, params)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -69,11 +66,8 @@ func SearchSkillsByName(name string) ([]Skill, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // querySkills executes a generic Cypher query and returns Skill nodes.
-func querySkills(cypher string, params map[string]interface{}) ([]Skill, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func querySkills(ctx context.Context, cypher string, params map[string]interface{}) ([]Skill, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, cypher, params)
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"go-ai/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConversationSummary is a rolling summary of the older turns of a user's
+// conversation, or of their history outside any conversation.
+type ConversationSummary struct {
+	UserID         string    `bson:"user_id"`
+	ConversationID string    `bson:"conversation_id,omitempty"`
+	Summary        string    `bson:"summary"`
+	CoveredUntil   time.Time `bson:"covered_until"` // timestamp of the last summarised message
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// summaryCollection returns the summary collection of the tenant ctx is scoped to.
+func summaryCollection(ctx context.Context) *mongo.Collection {
+	return database.Collection(tenant.FromContext(ctx).SummaryCollection)
+}
+
+// GetSummary returns the stored summary for a user's conversation, or nil if
+// none exists yet.
+func GetSummary(ctx context.Context, userID, conversationID string) (*ConversationSummary, error) {
+	var summary ConversationSummary
+	err := summaryCollection(ctx).FindOne(ctx, summaryFilter(userID, conversationID)).Decode(&summary)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// StoreSummary upserts the rolling summary for a user's conversation.
+func StoreSummary(ctx context.Context, summary ConversationSummary) error {
+	summary.UpdatedAt = time.Now()
+	_, err := summaryCollection(ctx).ReplaceOne(
+		ctx,
+		summaryFilter(summary.UserID, summary.ConversationID),
+		summary,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// EachSummary calls fn with every stored summary of the tenant ctx is scoped
+// to, stopping at the first error.
+func EachSummary(ctx context.Context, fn func(ConversationSummary) error) error {
+	cursor, err := summaryCollection(ctx).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var summary ConversationSummary
+		if err := cursor.Decode(&summary); err != nil {
+			return err
+		}
+		if err := fn(summary); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func summaryFilter(userID, conversationID string) bson.M {
+	return bson.M{"user_id": userID, "conversation_id": conversationFilter(conversationID)}
+}
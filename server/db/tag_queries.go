@@ -11,24 +11,24 @@ import (
 // ─────────────────────────────────────────────────────────────────────────────
 
 // GetAllTagsSorted returns all tags sorted alphabetically.
-func GetAllTagsSorted() ([]Tag, error) {
+func GetAllTagsSorted(ctx context.Context) ([]Tag, error) {
 	query := `
 		MATCH (t:Tag)
 		RETURN t.name AS name
 		ORDER BY name
 	`
-	return queryTags(query, nil)
+	return queryTags(ctx, query, nil)
 }
 
 // FindTagsBySkill returns tags linked to projects that use the given skill.
-func FindTagsBySkill(skill string) ([]Tag, error) {
+func FindTagsBySkill(ctx context.Context, skill string) ([]Tag, error) {
 	query := `
 		MATCH (t:Tag)<-[:HAS_TAG]-(p:Project)-[:USES]->(s:Skill {name: $skill})
 		RETURN DISTINCT t.name AS name
 		ORDER BY name
 	`
 	params := map[string]any{"skill": skill}
-	return queryTags(query, params)
+	return queryTags(ctx, query, params)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -36,15 +36,15 @@ func FindTagsBySkill(skill string) ([]Tag, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // queryTags executes a Cypher query and returns Tag nodes.
-func queryTags(cypher string, params map[string]any) ([]Tag, error) {
-	result, err := withReadSession(func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(context.Background(), cypher, params)
+func queryTags(ctx context.Context, cypher string, params map[string]any) ([]Tag, error) {
+	result, err := withReadSession(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
 			return nil, err
 		}
 
 		var tags []Tag
-		for res.Next(context.Background()) {
+		for res.Next(ctx) {
 			record := res.Record()
 			tags = append(tags, Tag{
 				Name: asString(record, "name"),
@@ -0,0 +1,49 @@
+package db
+
+// Plan validation outcomes recorded in PlanTrace.Outcome.
+const (
+	PlanValid    = "valid"    // accepted as returned
+	PlanRemapped = "remapped" // fixed locally via the synonym tables
+	PlanRepaired = "repaired" // fixed by sending validation errors back to the model
+	PlanDegraded = "degraded" // invalid parts dropped after the last repair attempt
+)
+
+// Planners recorded in PlanTrace.Planner.
+const (
+	PlannerLLM   = "llm"   // planned by the language model
+	PlannerRules = "rules" // planned by the rule-based fast path
+)
+
+// PlanTrace records how a graph query plan was produced so a reply can be
+// traced back to the plan that fed it. It is stored with the user message.
+type PlanTrace struct {
+	Attempts    int          `bson:"attempts" json:"attempts"`
+	Outcome     string       `bson:"outcome" json:"outcome"`
+	Remapped    []string     `bson:"remapped,omitempty" json:"remapped,omitempty"`
+	Errors      []string     `bson:"errors,omitempty" json:"errors,omitempty"`
+	Reasoning   string       `bson:"reasoning,omitempty" json:"reasoning,omitempty"`
+	Planner     string       `bson:"planner,omitempty" json:"planner,omitempty"`
+	Confidence  float64      `bson:"confidence,omitempty" json:"confidence,omitempty"`
+	Resolutions []Resolution `bson:"resolutions,omitempty" json:"resolutions,omitempty"`
+
+	// Context nodes cut short or left out to fit the answer model's token budget
+	ContextTruncated []string `bson:"context_truncated,omitempty" json:"context_truncated,omitempty"`
+	ContextDropped   []string `bson:"context_dropped,omitempty" json:"context_dropped,omitempty"`
+}
+
+// Entity resolution methods recorded in Resolution.Via.
+const (
+	ResolvedExact = "exact"
+	ResolvedAlias = "alias"
+	ResolvedFuzzy = "fuzzy"
+	ResolvedNone  = "none"
+)
+
+// Resolution records how one filter value was mapped to a canonical node name.
+type Resolution struct {
+	On       string  `bson:"on" json:"on"`
+	Value    string  `bson:"value" json:"value"`
+	Resolved string  `bson:"resolved,omitempty" json:"resolved,omitempty"` // empty when nothing scored high enough
+	Via      string  `bson:"via" json:"via"`
+	Score    float64 `bson:"score" json:"score"`
+}
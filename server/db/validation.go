@@ -0,0 +1,226 @@
+package db
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ValidationError reports a model field that can't be written to the graph.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+func validationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// GRAPH NODES
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (p Project) Label() string { return "Project" }
+func (p Project) Key() string   { return p.ID }
+
+func (p Project) Properties() map[string]any {
+	return map[string]any{
+		"id":            p.ID,
+		"name":          p.Name,
+		"description":   p.Description,
+		"institution":   p.Institution,
+		"image":         p.Image,
+		"featured":      p.Featured,
+		"contributions": nonNilStrings(p.Contributions),
+		"startDate":     p.StartDate,
+		"endDate":       p.EndDate,
+		"demo":          p.Demo,
+		"github":        p.GitHub,
+	}
+}
+
+func (p Project) Validate() error {
+	if err := requireFields(map[string]string{"id": p.ID, "name": p.Name, "description": p.Description}); err != nil {
+		return err
+	}
+	if err := validateDates(p.StartDate, p.EndDate); err != nil {
+		return err
+	}
+	if err := validateURL("demo", p.Demo); err != nil {
+		return err
+	}
+	return validateURL("github", p.GitHub)
+}
+
+func (w WorkExperience) Label() string { return "WorkExperience" }
+func (w WorkExperience) Key() string   { return w.ID }
+
+func (w WorkExperience) Properties() map[string]any {
+	return map[string]any{
+		"id":        w.ID,
+		"summary":   w.Summary,
+		"company":   w.Company,
+		"title":     w.Title,
+		"startDate": w.StartDate,
+		"endDate":   w.EndDate,
+		"featured":  w.Featured,
+	}
+}
+
+func (w WorkExperience) Validate() error {
+	if err := requireFields(map[string]string{"id": w.ID, "company": w.Company, "title": w.Title, "startDate": w.StartDate}); err != nil {
+		return err
+	}
+	return validateDates(w.StartDate, w.EndDate)
+}
+
+func (e Education) Label() string { return "Education" }
+func (e Education) Key() string   { return e.ID }
+
+func (e Education) Properties() map[string]any {
+	return map[string]any{
+		"id":          e.ID,
+		"summary":     e.Summary,
+		"institution": e.Institution,
+		"field":       e.Field,
+		"degree":      e.Degree,
+		"level":       e.Level,
+		"startDate":   e.StartDate,
+		"endDate":     e.EndDate,
+		"leadership":  nonNilStrings(e.Leadership),
+	}
+}
+
+func (e Education) Validate() error {
+	if err := requireFields(map[string]string{"id": e.ID, "institution": e.Institution}); err != nil {
+		return err
+	}
+	return validateDates(e.StartDate, e.EndDate)
+}
+
+func (c Course) Label() string { return "Course" }
+func (c Course) Key() string   { return c.ID }
+
+func (c Course) Properties() map[string]any {
+	return map[string]any{"id": c.ID, "title": c.Title, "description": c.Description}
+}
+
+func (c Course) Validate() error {
+	return requireFields(map[string]string{"id": c.ID, "title": c.Title})
+}
+
+func (h Hobby) Label() string { return "Hobby" }
+func (h Hobby) Key() string   { return h.Name }
+
+func (h Hobby) Properties() map[string]any {
+	return map[string]any{"name": h.Name, "description": h.Description}
+}
+
+func (h Hobby) Validate() error {
+	return requireFields(map[string]string{"name": h.Name})
+}
+
+func (s Skill) Label() string { return "Skill" }
+func (s Skill) Key() string   { return s.Name }
+
+func (s Skill) Properties() map[string]any {
+	return map[string]any{"name": s.Name}
+}
+
+func (s Skill) Validate() error {
+	return requireFields(map[string]string{"name": s.Name})
+}
+
+func (t Tag) Label() string { return "Tag" }
+func (t Tag) Key() string   { return t.Name }
+
+func (t Tag) Properties() map[string]any {
+	return map[string]any{"name": t.Name}
+}
+
+func (t Tag) Validate() error {
+	return requireFields(map[string]string{"name": t.Name})
+}
+
+func (p Person) Label() string { return "Person" }
+func (p Person) Key() string   { return p.ID }
+
+func (p Person) Properties() map[string]any {
+	return map[string]any{
+		"id":         p.ID,
+		"name":       p.Name,
+		"summary":    p.Summary,
+		"birthMonth": p.BirthMonth,
+		"birthYear":  p.BirthYear,
+		"background": nonNilStrings(p.Background),
+		"voiceTone":  p.VoiceTone,
+		"location":   p.Location,
+		"pronouns":   p.Pronouns,
+	}
+}
+
+func (p Person) Validate() error {
+	if err := requireFields(map[string]string{"id": p.ID, "name": p.Name}); err != nil {
+		return err
+	}
+	if p.BirthYear < 0 || p.BirthYear > 9999 {
+		return validationError("birthYear", "must be a four-digit year")
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// requireFields fails on the first blank field, checked in a stable order.
+func requireFields(fields map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if strings.TrimSpace(fields[name]) == "" {
+			return validationError(name, "is required")
+		}
+	}
+	return nil
+}
+
+// validateDates checks both dates use one of the DateLayouts. The end date may
+// also be empty or "Present".
+func validateDates(start, end string) error {
+	if start != "" {
+		if _, ok := ParseDate(start); !ok {
+			return validationError("startDate", fmt.Sprintf("%q is not a recognised date", start))
+		}
+	}
+	if end != "" && !strings.EqualFold(end, "present") {
+		if _, ok := ParseDate(end); !ok {
+			return validationError("endDate", fmt.Sprintf("%q is not a recognised date", end))
+		}
+	}
+	return nil
+}
+
+// validateURL checks an optional link is an absolute http(s) URL.
+func validateURL(field, link string) error {
+	if link == "" {
+		return nil
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return validationError(field, "must be an http(s) URL")
+	}
+	return nil
+}
+
+// nonNilStrings stores empty lists as [] so readers never see null.
+func nonNilStrings(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
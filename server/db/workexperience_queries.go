@@ -11,17 +11,17 @@ import (
 // ─────────────────────────────────────────────────────────────────────────────
 
 // GetAllWorkExperiencesSorted returns all WorkExperience nodes sorted by startDate.
-func GetAllWorkExperiencesSorted() ([]WorkExperience, error) {
+func GetAllWorkExperiencesSorted(ctx context.Context) ([]WorkExperience, error) {
 	query := `
 		MATCH (w:WorkExperience)
 		RETURN w
 		ORDER BY w.startDate
 	`
-	return queryWorkExperiences(query, nil)
+	return queryWorkExperiences(ctx, query, nil)
 }
 
 // SearchWorkExperiencesByCompany performs a case-insensitive search on company name.
-func SearchWorkExperiencesByCompany(company string) ([]WorkExperience, error) {
+func SearchWorkExperiencesByCompany(ctx context.Context, company string) ([]WorkExperience, error) {
 	query := `
 		MATCH (w:WorkExperience)
 		WHERE toLower(w.company) CONTAINS toLower($company)
@@ -29,11 +29,11 @@ func SearchWorkExperiencesByCompany(company string) ([]WorkExperience, error) {
 		ORDER BY w.startDate
 	`
 	params := map[string]interface{}{"company": company}
-	return queryWorkExperiences(query, params)
+	return queryWorkExperiences(ctx, query, params)
 }
 
 // SearchWorkExperiencesByName searches by company OR title.
-func SearchWorkExperiencesByName(name string) ([]WorkExperience, error) {
+func SearchWorkExperiencesByName(ctx context.Context, name string) ([]WorkExperience, error) {
 	query := `
 		MATCH (w:WorkExperience)
 		WHERE toLower(w.company) CONTAINS toLower($name)
@@ -41,24 +41,24 @@ func SearchWorkExperiencesByName(name string) ([]WorkExperience, error) {
 		RETURN w
 	`
 	params := map[string]interface{}{"name": name}
-	return queryWorkExperiences(query, params)
+	return queryWorkExperiences(ctx, query, params)
 }
 
 // FindWorkExperienceByTag returns work experiences associated with a given tag.
 // (unchanged)
-func FindWorkExperienceByTag(tag string) ([]WorkExperience, error) {
+func FindWorkExperienceByTag(ctx context.Context, tag string) ([]WorkExperience, error) {
 	query := `
 		MATCH (w:WorkExperience)-[:HAS_TAG]->(t:Tag)
 		WHERE toLower(t.name) = toLower($tag)
 		RETURN w
 	`
 	params := map[string]interface{}{"tag": tag}
-	return queryWorkExperiences(query, params)
+	return queryWorkExperiences(ctx, query, params)
 }
 
 // ListWorkExperienceCompanies extracts just the company names from all work experiences.
-func ListWorkExperienceCompanies() ([]string, error) {
-	work, err := GetAllWorkExperiencesSorted()
+func ListWorkExperienceCompanies(ctx context.Context) ([]string, error) {
+	work, err := GetAllWorkExperiencesSorted(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -70,12 +70,12 @@ func ListWorkExperienceCompanies() ([]string, error) {
 }
 
 // GetAllWorkExperiences returns all WorkExperience nodes without sorting.
-func GetAllWorkExperiences() ([]WorkExperience, error) {
+func GetAllWorkExperiences(ctx context.Context) ([]WorkExperience, error) {
 	query := `
 		MATCH (w:WorkExperience)
 		RETURN w
 	`
-	return queryWorkExperiences(query, nil)
+	return queryWorkExperiences(ctx, query, nil)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -83,11 +83,8 @@ func GetAllWorkExperiences() ([]WorkExperience, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // queryWorkExperiences executes a read transaction and maps WorkExperience nodes.
-func queryWorkExperiences(cypher string, params map[string]interface{}) ([]WorkExperience, error) {
-	ctx := context.Background()
-	session := Neo4jDriver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
+func queryWorkExperiences(ctx context.Context, cypher string, params map[string]interface{}) ([]WorkExperience, error) {
+	session := Neo4jDriver.NewSession(ctx, sessionConfig(ctx, neo4j.AccessModeRead))
 	defer session.Close(ctx)
 
 	result, err := session.Run(ctx, cypher, params)
@@ -2,25 +2,29 @@ module go-ai
 
 go 1.24.4
 
-require github.com/go-chi/httprate v0.15.0
+require (
+	github.com/go-chi/chi/v5 v5.2.1
+	github.com/go-chi/cors v1.2.1
+	github.com/go-chi/httprate v0.15.0
+	github.com/joho/godotenv v1.5.1
+	github.com/lithammer/fuzzysearch v1.1.8
+	github.com/neo4j/neo4j-go-driver/v5 v5.28.1
+	go.etcd.io/bbolt v1.4.3
+	go.mongodb.org/mongo-driver v1.17.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/go-chi/chi/v5 v5.2.1 // indirect
-	github.com/go-chi/cors v1.2.1 // indirect
 	github.com/golang/snappy v1.0.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/klauspost/compress v1.16.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
-	github.com/lithammer/fuzzysearch v1.1.8 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
-	github.com/neo4j/neo4j-go-driver/v5 v5.28.1 // indirect
 	github.com/sahilm/fuzzy v0.1.1 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
 	github.com/zeebo/xxh3 v1.0.2 // indirect
-	go.mongodb.org/mongo-driver v1.17.4 // indirect
 	go.mongodb.org/mongo-driver/v2 v2.2.2 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
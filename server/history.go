@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go-ai/db"
+)
+
+// ChatExport is a user's full chat history, as GET /chat/export returns it.
+type ChatExport struct {
+	UserID        string            `json:"userId"`
+	ExportedAt    time.Time         `json:"exportedAt"`
+	Conversations []db.Conversation `json:"conversations"`
+	Messages      []db.ChatMessage  `json:"messages"`
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// DELETE /chat — erases the visitor's chat history
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (s *server) handleDeleteChat(w http.ResponseWriter, r *http.Request) {
+	userId, ok := requestUser(w, r, r.URL.Query().Get("userId"))
+	if !ok {
+		return
+	}
+
+	if err := s.assistant.Chats.DeleteUserHistory(r.Context(), userId); err != nil {
+		writeChatStoreError(w, "delete chat history", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// GET /chat/export?format=json|markdown — downloads the visitor's chat history
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (s *server) handleExportChat(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userId, ok := requestUser(w, r, query.Get("userId"))
+	if !ok {
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "markdown" {
+		http.Error(w, "Unknown format (use json or markdown)", http.StatusBadRequest)
+		return
+	}
+
+	conversations, err := s.assistant.Chats.ListConversations(r.Context(), userId)
+	if err != nil {
+		writeChatStoreError(w, "export chat history", err)
+		return
+	}
+	messages, err := s.assistant.Chats.GetMessages(r.Context(), userId)
+	if err != nil {
+		writeChatStoreError(w, "export chat history", err)
+		return
+	}
+	export := ChatExport{
+		UserID:        userId,
+		ExportedAt:    time.Now().UTC(),
+		Conversations: conversations,
+		Messages:      messages,
+	}
+	if export.Conversations == nil {
+		export.Conversations = []db.Conversation{}
+	}
+	if export.Messages == nil {
+		export.Messages = []db.ChatMessage{}
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Disposition", `attachment; filename="chat-history.json"`)
+		writeJSON(w, http.StatusOK, export)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="chat-history.md"`)
+	_, _ = w.Write([]byte(renderChatMarkdown(export)))
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Internal: Markdown rendering
+// ─────────────────────────────────────────────────────────────────────────────
+
+// renderChatMarkdown renders an export as one section per conversation,
+// oldest first, after the messages sent outside any conversation.
+func renderChatMarkdown(export ChatExport) string {
+	byConversation := map[string][]db.ChatMessage{}
+	for _, msg := range export.Messages {
+		byConversation[msg.ConversationID] = append(byConversation[msg.ConversationID], msg)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Chat history\n\n")
+	b.WriteString(fmt.Sprintf("- User: %s\n", export.UserID))
+	b.WriteString(fmt.Sprintf("- Exported: %s\n", export.ExportedAt.Format(time.RFC3339)))
+
+	if messages := byConversation[""]; len(messages) > 0 {
+		writeMarkdownSection(&b, "Chat", messages)
+	}
+
+	conversations := slices.Clone(export.Conversations)
+	slices.SortStableFunc(conversations, func(a, b db.Conversation) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	for _, conv := range conversations {
+		title := conv.Title
+		if title == "" {
+			title = "Untitled conversation"
+		}
+		writeMarkdownSection(&b, title, byConversation[conv.ID])
+	}
+
+	if len(export.Messages) == 0 {
+		b.WriteString("\nNo messages.\n")
+	}
+	return b.String()
+}
+
+func writeMarkdownSection(b *strings.Builder, title string, messages []db.ChatMessage) {
+	b.WriteString(fmt.Sprintf("\n## %s\n", title))
+	for _, msg := range messages {
+		speaker := "You"
+		if msg.Role == "assistant" {
+			speaker = "Assistant"
+		}
+		b.WriteString(fmt.Sprintf("\n**%s** · %s\n\n%s\n", speaker, msg.Timestamp.UTC().Format("2006-01-02 15:04 MST"), msg.Content))
+	}
+}
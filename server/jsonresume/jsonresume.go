@@ -0,0 +1,342 @@
+package jsonresume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-ai/db"
+	"go-ai/tenant"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// SCHEMA — the parts of https://jsonresume.org/schema the graph can hold
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Resume is a JSON Resume document.
+type Resume struct {
+	Basics    Basics      `json:"basics"`
+	Work      []Work      `json:"work"`
+	Volunteer []Work      `json:"volunteer"`
+	Education []Education `json:"education"`
+	Skills    []Skill     `json:"skills"`
+	Interests []Interest  `json:"interests"`
+	Projects  []Project   `json:"projects"`
+}
+
+type Basics struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label"`
+	Summary  string   `json:"summary"`
+	Location Location `json:"location"`
+}
+
+type Location struct {
+	City        string `json:"city"`
+	Region      string `json:"region"`
+	CountryCode string `json:"countryCode"`
+}
+
+type Work struct {
+	Name         string   `json:"name"`
+	Company      string   `json:"company"` // pre-1.0 name for Name
+	Organization string   `json:"organization"`
+	Position     string   `json:"position"`
+	Summary      string   `json:"summary"`
+	Highlights   []string `json:"highlights"`
+	StartDate    string   `json:"startDate"`
+	EndDate      string   `json:"endDate"`
+}
+
+type Education struct {
+	Institution string   `json:"institution"`
+	Area        string   `json:"area"`
+	StudyType   string   `json:"studyType"`
+	StartDate   string   `json:"startDate"`
+	EndDate     string   `json:"endDate"`
+	Courses     []string `json:"courses"`
+}
+
+type Skill struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+type Interest struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+type Project struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Highlights  []string `json:"highlights"`
+	Keywords    []string `json:"keywords"`
+	StartDate   string   `json:"startDate"`
+	EndDate     string   `json:"endDate"`
+	URL         string   `json:"url"`
+	Entity      string   `json:"entity"`
+}
+
+// Parse reads a JSON Resume document.
+func Parse(r io.Reader) (Resume, error) {
+	var resume Resume
+	if err := json.NewDecoder(r).Decode(&resume); err != nil {
+		return Resume{}, fmt.Errorf("invalid JSON Resume: %w", err)
+	}
+	if strings.TrimSpace(resume.Basics.Name) == "" {
+		return Resume{}, fmt.Errorf("invalid JSON Resume: basics.name is required")
+	}
+	return resume, nil
+}
+
+// Import upserts a JSON Resume into the graph of the tenant ctx is scoped to
+// and returns what changed. Entries the graph can't hold are left out and
+// listed in the changeset's Skipped. With dryRun the changeset is only planned.
+func Import(ctx context.Context, r io.Reader, dryRun bool) (db.Changeset, error) {
+	resume, err := Parse(r)
+	if err != nil {
+		return db.Changeset{}, err
+	}
+	g, skipped := importable(resume.Graph(tenant.FromContext(ctx).PersonID))
+	cs, err := db.PlanChangeset(ctx, g, db.PlanUpsert)
+	cs.Skipped = skipped
+	if err != nil || dryRun {
+		return cs, err
+	}
+	return cs, db.ApplyChangeset(ctx, cs)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// GRAPH MAPPING
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Graph maps the resume onto resume graph nodes. IDs are derived from names
+// and start dates, so importing the same resume twice touches the same nodes.
+// personID overrides the Person's ID, e.g. with the tenant's configured one.
+//
+//   - work and volunteer entries become WorkExperience
+//   - skill keywords (or the skill name when it has none) become Skill
+//   - project keywords naming a skill become USES, the rest Tag via HAS_TAG
+//   - a project whose entity names an employer is linked WORKED_ON to it
+//   - interests become Hobby
+func (r Resume) Graph(personID string) db.ResumeGraph {
+	var g db.ResumeGraph
+
+	if personID == "" {
+		personID = slug(r.Basics.Name)
+	}
+	g.Person = &db.Person{
+		ID:         personID,
+		Name:       r.Basics.Name,
+		Summary:    r.Basics.Summary,
+		Location:   joinNonEmpty(", ", r.Basics.Location.City, r.Basics.Location.Region, r.Basics.Location.CountryCode),
+		Background: nonEmpty(r.Basics.Label),
+	}
+
+	employers := map[string]string{}
+	for _, w := range append(r.Work, r.Volunteer...) {
+		company := db.FirstNonEmpty(w.Name, w.Company, w.Organization)
+		id := "work-" + slug(company+" "+w.StartDate)
+		if _, dup := employers[strings.ToLower(company)]; !dup {
+			employers[strings.ToLower(company)] = id
+		}
+		g.WorkExperience = append(g.WorkExperience, db.WorkExperience{
+			ID:        id,
+			Company:   company,
+			Title:     w.Position,
+			Summary:   joinNonEmpty(" ", append([]string{w.Summary}, w.Highlights...)...),
+			StartDate: w.StartDate,
+			EndDate:   w.EndDate,
+		})
+	}
+
+	for _, e := range r.Education {
+		g.Education = append(g.Education, db.Education{
+			ID:          "education-" + slug(e.Institution+" "+e.Area+" "+e.StartDate),
+			Institution: e.Institution,
+			Field:       e.Area,
+			Degree:      e.StudyType,
+			Summary:     joinNonEmpty(", ", e.Courses...),
+			StartDate:   e.StartDate,
+			EndDate:     e.EndDate,
+		})
+	}
+
+	skills := newNameSet()
+	for _, s := range r.Skills {
+		names := s.Keywords
+		if len(names) == 0 {
+			names = []string{s.Name}
+		}
+		for _, name := range names {
+			skills.add(name)
+		}
+	}
+
+	tags := newNameSet()
+	for _, p := range r.Projects {
+		id := "project-" + slug(p.Name)
+		project := db.Project{
+			ID:            id,
+			Name:          p.Name,
+			Description:   db.FirstNonEmpty(p.Description, joinNonEmpty(" ", p.Highlights...)),
+			Institution:   p.Entity,
+			Contributions: p.Highlights,
+			StartDate:     p.StartDate,
+			EndDate:       p.EndDate,
+		}
+		if isGitHub(p.URL) {
+			project.GitHub = p.URL
+		} else {
+			project.Demo = p.URL
+		}
+		g.Projects = append(g.Projects, project)
+
+		from := db.NodeRef{Label: "Project", Key: id}
+		for _, keyword := range p.Keywords {
+			if name, ok := skills.get(keyword); ok {
+				g.Relationships = append(g.Relationships, db.Relationship{Type: "USES", From: from, To: db.NodeRef{Label: "Skill", Key: name}})
+			} else if name := tags.add(keyword); name != "" {
+				g.Relationships = append(g.Relationships, db.Relationship{Type: "HAS_TAG", From: from, To: db.NodeRef{Label: "Tag", Key: name}})
+			}
+		}
+		if workID, ok := employers[strings.ToLower(p.Entity)]; ok {
+			g.Relationships = append(g.Relationships, db.Relationship{Type: "WORKED_ON", From: from, To: db.NodeRef{Label: "WorkExperience", Key: workID}})
+		}
+	}
+
+	for _, name := range skills.names {
+		g.Skills = append(g.Skills, db.Skill{Name: name})
+	}
+	for _, name := range tags.names {
+		g.Tags = append(g.Tags, db.Tag{Name: name})
+	}
+	for _, i := range r.Interests {
+		if strings.TrimSpace(i.Name) != "" {
+			g.Hobbies = append(g.Hobbies, db.Hobby{Name: i.Name, Description: joinNonEmpty(", ", i.Keywords...)})
+		}
+	}
+	return g
+}
+
+// importable drops the nodes that fail validation, and the relationships to
+// them, so one sparse entry doesn't reject the whole resume. JSON Resume makes
+// every field optional, while the graph needs e.g. a position and start date
+// for work and a description for projects. It returns why each node was
+// dropped. The Person is kept either way, for PlanChangeset to reject.
+func importable(g db.ResumeGraph) (db.ResumeGraph, []string) {
+	var skipped []string
+	dropped := map[db.NodeRef]bool{}
+	valid := func(n db.GraphNode) bool {
+		err := n.Validate()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s %q: %v", n.Label(), n.Key(), err))
+			dropped[db.NodeRef{Label: n.Label(), Key: n.Key()}] = true
+		}
+		return err == nil
+	}
+
+	g.Projects = keepValid(g.Projects, valid)
+	g.WorkExperience = keepValid(g.WorkExperience, valid)
+	g.Education = keepValid(g.Education, valid)
+	g.Hobbies = keepValid(g.Hobbies, valid)
+	g.Skills = keepValid(g.Skills, valid)
+	g.Tags = keepValid(g.Tags, valid)
+
+	var relationships []db.Relationship
+	tagged := map[string]bool{}
+	for _, r := range g.Relationships {
+		if dropped[r.From] || dropped[r.To] {
+			continue
+		}
+		relationships = append(relationships, r)
+		if r.To.Label == "Tag" {
+			tagged[r.To.Key] = true
+		}
+	}
+	g.Relationships = relationships
+
+	// Tags come from project keywords alone, so drop the skipped projects' ones
+	var tags []db.Tag
+	for _, t := range g.Tags {
+		if tagged[t.Name] {
+			tags = append(tags, t)
+		}
+	}
+	g.Tags = tags
+	return g, skipped
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// keepValid returns the nodes valid accepts, in order.
+func keepValid[T db.GraphNode](nodes []T, valid func(db.GraphNode) bool) []T {
+	var kept []T
+	for _, n := range nodes {
+		if valid(n) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// nameSet keeps the first spelling of each name, matched case-insensitively.
+type nameSet struct {
+	byKey map[string]string
+	names []string
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{byKey: map[string]string{}}
+}
+
+// add records name and returns the spelling kept for it ("" for a blank name).
+func (s *nameSet) add(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	if kept, ok := s.byKey[strings.ToLower(name)]; ok {
+		return kept
+	}
+	s.byKey[strings.ToLower(name)] = name
+	s.names = append(s.names, name)
+	return name
+}
+
+func (s *nameSet) get(name string) (string, bool) {
+	kept, ok := s.byKey[strings.ToLower(strings.TrimSpace(name))]
+	return kept, ok
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a name into a lowercase, dash-separated ID fragment.
+func slug(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func isGitHub(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && strings.EqualFold(strings.TrimPrefix(u.Host, "www."), "github.com")
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	return strings.Join(nonEmpty(values...), sep)
+}
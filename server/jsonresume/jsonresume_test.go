@@ -0,0 +1,72 @@
+package jsonresume
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go-ai/db"
+)
+
+// minimalResume leaves out everything JSON Resume makes optional: the first
+// job has no start date, the second project no description, the school no
+// dates at all.
+const minimalResume = `{
+	"basics": {"name": "Ada Example"},
+	"work": [
+		{"name": "Initech", "position": "Engineer"},
+		{"name": "Acme", "position": "Intern", "startDate": "2022-06"}
+	],
+	"education": [{"institution": "State University"}],
+	"skills": [{"name": "Go"}],
+	"projects": [
+		{"name": "Atlas", "description": "A map tile server", "keywords": ["Go"]},
+		{"name": "Sketch", "keywords": ["Go", "Prototype"], "entity": "Acme"}
+	],
+	"interests": [{"name": "Chess"}]
+}`
+
+func TestImportableSkipsSparseEntries(t *testing.T) {
+	resume, err := Parse(strings.NewReader(minimalResume))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, skipped := importable(resume.Graph(""))
+
+	if g.Person == nil || g.Person.ID != "ada-example" {
+		t.Errorf("person = %+v", g.Person)
+	}
+	var kept []string
+	for _, n := range g.Nodes() {
+		kept = append(kept, n.Label()+" "+n.Key())
+	}
+	want := []string{
+		"Person ada-example",
+		"Project project-atlas",
+		"WorkExperience work-acme-2022-06",
+		"Education education-state-university",
+		"Hobby Chess",
+		"Skill Go",
+	}
+	if !slices.Equal(kept, want) {
+		t.Errorf("kept %q, want %q", kept, want)
+	}
+
+	wantSkipped := []string{
+		`Project "project-sketch": invalid description: is required`,
+		`WorkExperience "work-initech": invalid startDate: is required`,
+	}
+	if !slices.Equal(skipped, wantSkipped) {
+		t.Errorf("skipped %q, want %q", skipped, wantSkipped)
+	}
+
+	for _, r := range g.Relationships {
+		if r.From.Key == "project-sketch" || r.To.Key == "project-sketch" {
+			t.Errorf("kept %s relationship of the skipped project", r.Type)
+		}
+	}
+	uses := db.Relationship{Type: "USES", From: db.NodeRef{Label: "Project", Key: "project-atlas"}, To: db.NodeRef{Label: "Skill", Key: "Go"}}
+	if !slices.Contains(g.Relationships, uses) {
+		t.Errorf("relationships %+v lack Atlas USES Go", g.Relationships)
+	}
+}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+)
+
+// FakeRule maps a substring of the prompt to a canned reply.
+type FakeRule struct {
+	Match string
+	Reply string
+}
+
+// Fake is a deterministic, offline provider for tests and local development.
+// Rules are checked in order against the prompt (or the last message for Chat);
+// when none match, Generate returns "{}" and Chat echoes the last message.
+// Embeddings are hashed bag-of-words vectors, so similar text scores higher.
+type Fake struct {
+	ModelName  string
+	Rules      []FakeRule
+	Dimensions int
+
+	mu    sync.Mutex
+	Calls []string
+}
+
+// NewFake returns a Fake with no rules and 64-dimension embeddings.
+func NewFake(model string) *Fake {
+	if model == "" {
+		model = "fake"
+	}
+	return &Fake{ModelName: model, Dimensions: 64}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PROVIDER
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (f *Fake) Model() string {
+	return f.ModelName
+}
+
+func (f *Fake) Chat(_ context.Context, messages []Message) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("fake: no messages")
+	}
+	last := messages[len(messages)-1].Content
+	if reply, ok := f.match(last); ok {
+		return reply, nil
+	}
+	return fmt.Sprintf("[%s] %s", f.ModelName, last), nil
+}
+
+// ChatStream emits the Chat reply one word at a time.
+func (f *Fake) ChatStream(ctx context.Context, messages []Message, onToken TokenFunc) (string, error) {
+	reply, err := f.Chat(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+
+	var full strings.Builder
+	for _, word := range strings.SplitAfter(reply, " ") {
+		if err := ctx.Err(); err != nil {
+			return full.String(), err
+		}
+		if word == "" {
+			continue
+		}
+		full.WriteString(word)
+		if err := onToken(word); err != nil {
+			return full.String(), err
+		}
+	}
+	return full.String(), nil
+}
+
+func (f *Fake) Generate(_ context.Context, prompt string) (string, error) {
+	if reply, ok := f.match(prompt); ok {
+		return reply, nil
+	}
+	return "{}", nil
+}
+
+// GenerateJSON ignores the schema; rules are expected to return valid JSON.
+func (f *Fake) GenerateJSON(ctx context.Context, prompt string, _ JSONSchema) (string, error) {
+	return f.Generate(ctx, prompt)
+}
+
+func (f *Fake) Embed(_ context.Context, inputs []string) ([][]float64, error) {
+	dims := f.Dimensions
+	if dims <= 0 {
+		dims = 64
+	}
+	vectors := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		vec := make([]float64, dims)
+		for _, word := range strings.Fields(strings.ToLower(input)) {
+			h := fnv.New32a()
+			h.Write([]byte(strings.Trim(word, ".,!?;:\"'()")))
+			vec[h.Sum32()%uint32(dims)]++
+		}
+		var norm float64
+		for _, v := range vec {
+			norm += v * v
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for j := range vec {
+				vec[j] /= norm
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPER
+// ─────────────────────────────────────────────────────────────────────────────
+
+// match records the prompt and returns the first rule reply whose Match it contains.
+func (f *Fake) match(prompt string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, prompt)
+	for _, r := range f.Rules {
+		if strings.Contains(prompt, r.Match) {
+			return r.Reply, true
+		}
+	}
+	return "", false
+}
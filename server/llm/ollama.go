@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-ai/config"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// TYPES
+// ─────────────────────────────────────────────────────────────────────────────
+
+type ollamaGenerateRequest struct {
+	Model  string         `json:"model"`
+	Prompt string         `json:"prompt"`
+	Stream bool           `json:"stream"`
+	Format map[string]any `json:"format,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+}
+
+type ollamaChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type ollamaChatResponse struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Ollama talks to a local or remote Ollama server.
+type Ollama struct {
+	BaseURL        string
+	ChatModel      string
+	EmbeddingModel string
+}
+
+// NewOllama builds a provider pointed at OLLAMA_URI. It chats and embeds with
+// the one model it is built for, which is the embedding model when ForRole
+// builds it for RoleEmbedder.
+func NewOllama(model string) *Ollama {
+	return &Ollama{
+		BaseURL:        config.GetOllamaURI(),
+		ChatModel:      model,
+		EmbeddingModel: model,
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PROVIDER
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (o *Ollama) Model() string {
+	return o.ChatModel
+}
+
+// Chat calls /api/chat with streaming disabled.
+func (o *Ollama) Chat(ctx context.Context, messages []Message) (string, error) {
+	body, err := o.post(ctx, "/api/chat", ollamaChatRequest{
+		Model:    o.ChatModel,
+		Messages: messages,
+		Stream:   false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result ollamaChatResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("Unmarshal failed: %w\nRaw body: %s", err, string(body))
+	}
+	return result.Message.Content, nil
+}
+
+// ChatStream calls /api/chat with streaming enabled and reads the
+// newline-delimited JSON chunks until one reports done.
+func (o *Ollama) ChatStream(ctx context.Context, messages []Message, onToken TokenFunc) (string, error) {
+	resp, err := o.send(ctx, "/api/chat", ollamaChatRequest{
+		Model:    o.ChatModel,
+		Messages: messages,
+		Stream:   true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return full.String(), fmt.Errorf("failed to parse Ollama stream chunk: %w", err)
+		}
+		if token := chunk.Message.Content; token != "" {
+			full.WriteString(token)
+			if err := onToken(token); err != nil {
+				return full.String(), err
+			}
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return full.String(), err
+	}
+	return full.String(), ctx.Err()
+}
+
+// Generate calls /api/generate with streaming disabled.
+func (o *Ollama) Generate(ctx context.Context, prompt string) (string, error) {
+	return o.generate(ctx, ollamaGenerateRequest{
+		Model:  o.ChatModel,
+		Prompt: prompt,
+		Stream: false,
+	})
+}
+
+// GenerateJSON passes the schema as the format parameter so Ollama constrains
+// decoding to matching JSON.
+func (o *Ollama) GenerateJSON(ctx context.Context, prompt string, schema JSONSchema) (string, error) {
+	return o.generate(ctx, ollamaGenerateRequest{
+		Model:  o.ChatModel,
+		Prompt: prompt,
+		Stream: false,
+		Format: schema.Schema,
+	})
+}
+
+// generate sends a non-streaming /api/generate request and returns the response text.
+func (o *Ollama) generate(ctx context.Context, reqBody ollamaGenerateRequest) (string, error) {
+	body, err := o.post(ctx, "/api/generate", reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var result ollamaGenerateResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("Unmarshal failed: %w\nRaw body: %s", err, string(body))
+	}
+	return result.Response, nil
+}
+
+// Embed calls /api/embed, which accepts a batch of inputs.
+func (o *Ollama) Embed(ctx context.Context, inputs []string) ([][]float64, error) {
+	body, err := o.post(ctx, "/api/embed", ollamaEmbedRequest{
+		Model: o.EmbeddingModel,
+		Input: inputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result ollamaEmbedResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("Unmarshal failed: %w\nRaw body: %s", err, string(body))
+	}
+	if len(result.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d inputs", len(result.Embeddings), len(inputs))
+	}
+	return result.Embeddings, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPER
+// ─────────────────────────────────────────────────────────────────────────────
+
+// post sends a JSON payload to the given Ollama path and returns the raw body.
+func (o *Ollama) post(ctx context.Context, path string, payload any) ([]byte, error) {
+	resp, err := o.send(ctx, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Ollama response body: %w", err)
+	}
+	return body, nil
+}
+
+// send posts a JSON payload to the given Ollama path and returns the open
+// response. Non-200 responses are read and turned into an error.
+func (o *Ollama) send(ctx context.Context, path string, payload any) (*http.Response, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama POST request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama error (%d): %s", resp.StatusCode, body)
+	}
+	return resp, nil
+}
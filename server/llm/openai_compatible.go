@@ -0,0 +1,252 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-ai/config"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// TYPES
+// ─────────────────────────────────────────────────────────────────────────────
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []Message             `json:"messages"`
+	Stream         bool                  `json:"stream,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// OpenAICompatible talks to any backend that speaks the OpenAI chat-completions
+// and embeddings wire format.
+type OpenAICompatible struct {
+	ChatURL        string
+	EmbeddingURL   string
+	APIKey         string
+	ChatModel      string
+	EmbeddingModel string
+}
+
+// NewOpenAICompatible builds a provider from the OPENAI_* environment variables.
+// The embedding URL is optional so chat-only deployments don't need it. Like
+// NewOllama, it chats and embeds with the one model it is built for.
+func NewOpenAICompatible(model string) *OpenAICompatible {
+	return &OpenAICompatible{
+		ChatURL:        config.GetOpenAIChatURL(),
+		EmbeddingURL:   config.GetOptionalOpenAIEmbeddingURL(),
+		APIKey:         config.GetOpenAIKey(),
+		ChatModel:      model,
+		EmbeddingModel: model,
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PROVIDER
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (o *OpenAICompatible) Model() string {
+	return o.ChatModel
+}
+
+// Chat sends a chat-completions request and returns the first choice.
+func (o *OpenAICompatible) Chat(ctx context.Context, messages []Message) (string, error) {
+	return o.complete(ctx, openAIChatRequest{
+		Model:    o.ChatModel,
+		Messages: messages,
+	})
+}
+
+// complete sends a non-streaming chat-completions request and returns the first choice.
+func (o *OpenAICompatible) complete(ctx context.Context, reqBody openAIChatRequest) (string, error) {
+	body, err := o.post(ctx, o.ChatURL, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var apiResp openAIChatResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return "", err
+	}
+	if len(apiResp.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI returned no choices: %s", body)
+	}
+	return apiResp.Choices[0].Message.Content, nil
+}
+
+// ChatStream sends a chat-completions request with stream enabled and reads the
+// server-sent "data:" lines until [DONE].
+func (o *OpenAICompatible) ChatStream(ctx context.Context, messages []Message, onToken TokenFunc) (string, error) {
+	resp, err := o.send(ctx, o.ChatURL, openAIChatRequest{
+		Model:    o.ChatModel,
+		Messages: messages,
+		Stream:   true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return full.String(), fmt.Errorf("failed to parse OpenAI stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		token := chunk.Choices[0].Delta.Content
+		full.WriteString(token)
+		if err := onToken(token); err != nil {
+			return full.String(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return full.String(), err
+	}
+	return full.String(), ctx.Err()
+}
+
+// Generate wraps the prompt in a single user message.
+func (o *OpenAICompatible) Generate(ctx context.Context, prompt string) (string, error) {
+	return o.Chat(ctx, []Message{{Role: "user", Content: prompt}})
+}
+
+// GenerateJSON uses response_format with a strict JSON schema.
+func (o *OpenAICompatible) GenerateJSON(ctx context.Context, prompt string, schema JSONSchema) (string, error) {
+	return o.complete(ctx, openAIChatRequest{
+		Model:    o.ChatModel,
+		Messages: []Message{{Role: "user", Content: prompt}},
+		ResponseFormat: &openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openAIJSONSchema{
+				Name:   schema.Name,
+				Schema: schema.Schema,
+				Strict: true,
+			},
+		},
+	})
+}
+
+// Embed calls the embeddings endpoint and returns vectors in input order.
+func (o *OpenAICompatible) Embed(ctx context.Context, inputs []string) ([][]float64, error) {
+	if o.EmbeddingURL == "" {
+		return nil, fmt.Errorf("OPENAI_EMBEDDING_URL not set")
+	}
+	body, err := o.post(ctx, o.EmbeddingURL, openAIEmbeddingRequest{
+		Model: o.EmbeddingModel,
+		Input: inputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp openAIEmbeddingResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float64, len(inputs))
+	for _, d := range apiResp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	return vectors, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPER
+// ─────────────────────────────────────────────────────────────────────────────
+
+// post marshals the payload, sends it with the bearer token and returns the raw body.
+func (o *OpenAICompatible) post(ctx context.Context, url string, payload any) ([]byte, error) {
+	resp, err := o.send(ctx, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// send marshals the payload, sends it with the bearer token and returns the open
+// response. Non-200 responses are read and turned into an error.
+func (o *OpenAICompatible) send(ctx context.Context, url string, payload any) (*http.Response, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenAI error: %s", body)
+	}
+	return resp, nil
+}
@@ -0,0 +1,216 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"go-ai/config"
+	"go-ai/tenant"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// TYPES
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Message is a single chat turn in a provider-neutral format.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Provider is implemented by every model backend the pipeline can talk to.
+type Provider interface {
+	// Model returns the model name requests are sent to.
+	Model() string
+	// Chat sends a list of messages and returns the assistant reply.
+	Chat(ctx context.Context, messages []Message) (string, error)
+	// ChatStream sends a list of messages and calls onToken for every chunk as it
+	// arrives. It returns the text streamed so far, even when it stops early
+	// because ctx was cancelled or onToken returned an error.
+	ChatStream(ctx context.Context, messages []Message, onToken TokenFunc) (string, error)
+	// Generate sends a single raw prompt and returns the completion.
+	Generate(ctx context.Context, prompt string) (string, error)
+	// GenerateJSON is Generate constrained to JSON matching the schema.
+	GenerateJSON(ctx context.Context, prompt string, schema JSONSchema) (string, error)
+	// Embed returns one vector per input string.
+	Embed(ctx context.Context, inputs []string) ([][]float64, error)
+}
+
+// JSONSchema describes the shape structured output must follow.
+type JSONSchema struct {
+	Name   string         // identifier some backends require, e.g. "graph_query_plan"
+	Schema map[string]any // a JSON Schema object
+}
+
+// TokenFunc receives each streamed chunk of a reply.
+type TokenFunc func(token string) error
+
+// Role identifies which stage of the pipeline a provider is used for.
+type Role string
+
+const (
+	RolePlanner  Role = "planner"
+	RoleAnswerer Role = "answerer"
+	RoleEmbedder Role = "embedder"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// REGISTRY
+// ─────────────────────────────────────────────────────────────────────────────
+
+var (
+	mu        sync.RWMutex
+	overrides = map[Role]Provider{}
+	providers = map[string]Provider{} // built providers by kind and model
+)
+
+// ForRole returns the provider configured for the given role. An override set
+// with Use takes precedence over the models of the tenant ctx is scoped to,
+// which take precedence over the environment configuration. Providers are
+// built once and reused; one that can't be built fails every call instead.
+func ForRole(ctx context.Context, role Role) Provider {
+	mu.RLock()
+	p, ok := overrides[role]
+	mu.RUnlock()
+	if ok {
+		return p
+	}
+
+	kind, model := roleModel(ctx, role)
+	p, err := provider(kind, model)
+	if err != nil {
+		// ValidateConfig builds every configured provider at startup, so this
+		// only fires when it didn't run, e.g. in tests
+		log.Printf("[ERROR] No %s provider: %v", role, err)
+		return unavailable{err: err}
+	}
+	return p
+}
+
+// Use overrides the provider for a role, e.g. to run the pipeline against a Fake.
+func Use(role Role, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p == nil {
+		delete(overrides, role)
+		return
+	}
+	overrides[role] = p
+}
+
+// New builds a provider by kind ("openai", "ollama" or "fake") for the given
+// model, failing when the environment the kind needs is missing.
+func New(kind, model string) (Provider, error) {
+	switch strings.ToLower(kind) {
+	case "openai":
+		if err := config.MissingEnv("OPENAI_API_KEY", "OPENAI_API_URL"); err != nil {
+			return nil, fmt.Errorf("openai provider: %w", err)
+		}
+		return NewOpenAICompatible(model), nil
+	case "ollama":
+		if err := config.MissingEnv("OLLAMA_URI"); err != nil {
+			return nil, fmt.Errorf("ollama provider: %w", err)
+		}
+		return NewOllama(model), nil
+	case "fake":
+		return NewFake(model), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", kind)
+	}
+}
+
+// ValidateConfig builds the provider of every role for every tenant, so a
+// misconfigured role fails at startup rather than on the first chat.
+func ValidateConfig() error {
+	for _, t := range tenant.All() {
+		ctx := tenant.NewContext(context.Background(), t)
+		for _, role := range []Role{RolePlanner, RoleAnswerer, RoleEmbedder} {
+			if _, err := provider(roleModel(ctx, role)); err != nil {
+				return fmt.Errorf("%s of tenant %s: %w", role, t.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// roleModel resolves the provider kind and model of a role for the tenant ctx
+// is scoped to.
+func roleModel(ctx context.Context, role Role) (kind, model string) {
+	switch role {
+	case RolePlanner:
+		kind, model = config.GetPlannerProvider(), config.GetPlannerModel()
+	case RoleEmbedder:
+		kind, model = config.GetEmbeddingProvider(), config.GetEmbeddingModel()
+	default:
+		kind, model = config.GetAnswerProvider(), config.GetAnswerModel()
+	}
+	if m, ok := tenant.FromContext(ctx).Model(string(role)); ok {
+		if m.Provider != "" {
+			kind = m.Provider
+		}
+		if m.Model != "" {
+			model = m.Model
+		}
+	}
+	return kind, model
+}
+
+// provider returns the cached provider for kind and model, building it on
+// first use.
+func provider(kind, model string) (Provider, error) {
+	key := strings.ToLower(kind) + "/" + model
+	mu.RLock()
+	p, ok := providers[key]
+	mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+
+	p, err := New(kind, model)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := providers[key]; ok {
+		return existing, nil
+	}
+	providers[key] = p
+	return p, nil
+}
+
+// unavailable stands in for a provider that couldn't be built and fails
+// every call with the reason.
+type unavailable struct {
+	err error
+}
+
+func (u unavailable) Model() string {
+	return ""
+}
+
+func (u unavailable) Chat(context.Context, []Message) (string, error) {
+	return "", u.err
+}
+
+func (u unavailable) ChatStream(context.Context, []Message, TokenFunc) (string, error) {
+	return "", u.err
+}
+
+func (u unavailable) Generate(context.Context, string) (string, error) {
+	return "", u.err
+}
+
+func (u unavailable) GenerateJSON(context.Context, string, JSONSchema) (string, error) {
+	return "", u.err
+}
+
+func (u unavailable) Embed(context.Context, []string) ([][]float64, error) {
+	return nil, u.err
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-ai/tenant"
+)
+
+func TestForRoleEmbedsWithTheTenantsModel(t *testing.T) {
+	var models []string
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		models = append(models, req.Model)
+		_ = json.NewEncoder(w).Encode(ollamaEmbedResponse{Embeddings: [][]float64{{1, 0}}})
+	}))
+	defer ollama.Close()
+	t.Setenv("OLLAMA_URI", ollama.URL)
+	t.Setenv("EMBEDDING_PROVIDER", "ollama")
+	t.Setenv("EMBEDDING_MODEL", "nomic-embed-text")
+
+	tenants := []*tenant.Tenant{
+		{ID: "default"},
+		{ID: "custom", Models: map[string]tenant.ModelSetting{string(RoleEmbedder): {Model: "mxbai-embed-large"}}},
+	}
+	for _, tn := range tenants {
+		ctx := tenant.NewContext(context.Background(), tn)
+		if _, err := ForRole(ctx, RoleEmbedder).Embed(ctx, []string{"Go"}); err != nil {
+			t.Fatalf("%s: %v", tn.ID, err)
+		}
+	}
+
+	if len(models) != 2 || models[0] != "nomic-embed-text" || models[1] != "mxbai-embed-large" {
+		t.Errorf("embedded with %q, want the default model and then the tenant's", models)
+	}
+}
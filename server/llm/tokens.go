@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// messageOverhead approximates the per-message framing tokens chat APIs add.
+const messageOverhead = 4
+
+// Tokenizer counts tokens the way a model's tokenizer splits text. Providers
+// that can count exactly implement it; the rest get an estimate calibrated for
+// their model family (see TokenizerFor).
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// EstimateTokens approximates the token count of text using the ~4 characters
+// per token rule of thumb for BPE tokenizers, for models with no better
+// estimate in tokenizers.
+func EstimateTokens(text string) int {
+	return defaultTokenizer.CountTokens(text)
+}
+
+// EstimateMessageTokens approximates the token count of a single chat message.
+func EstimateMessageTokens(m Message) int {
+	return EstimateTokens(m.Content) + messageOverhead
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// PER-MODEL ESTIMATES
+// ─────────────────────────────────────────────────────────────────────────────
+
+// bpePieces splits text the way tiktoken's encodings do before merging bytes:
+// a word with its leading space, up to three digits, a punctuation run, or
+// whitespace.
+var bpePieces = regexp.MustCompile(`'(?:s|t|re|ve|m|ll|d)| ?\pL+| ?\pN{1,3}| ?[^\s\pL\pN]+|\s+`)
+
+// bpeEstimate estimates tiktoken-style encodings without their merge tables.
+// Each pre-tokenized piece costs one token per wordBytes bytes, so common
+// words are one token while long or non-Latin ones split as they would.
+type bpeEstimate struct {
+	wordBytes float64
+}
+
+func (e bpeEstimate) CountTokens(text string) int {
+	n := 0
+	for _, piece := range bpePieces.FindAllString(text, -1) {
+		n += int(math.Ceil(float64(len(piece)) / e.wordBytes))
+	}
+	return n
+}
+
+// ratioEstimate estimates SentencePiece tokenizers, which don't pre-split
+// text, from the average characters per token of their vocabulary.
+type ratioEstimate struct {
+	charsPerToken float64
+}
+
+func (e ratioEstimate) CountTokens(text string) int {
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / e.charsPerToken))
+}
+
+var (
+	cl100k = bpeEstimate{wordBytes: 6} // gpt-4, gpt-3.5-turbo, text-embedding-3
+	o200k  = bpeEstimate{wordBytes: 7} // gpt-4o, gpt-4.1 and the o-series
+
+	defaultTokenizer = ratioEstimate{charsPerToken: 4}
+)
+
+// tokenizers are the estimates for model families, matched by prefix like
+// contextWindows, so more specific prefixes come first.
+var tokenizers = []struct {
+	Prefix    string
+	Tokenizer Tokenizer
+}{
+	{"gpt-4o", o200k},
+	{"gpt-4.1", o200k},
+	{"o1", o200k},
+	{"o3", o200k},
+	{"o4", o200k},
+	{"gpt-4", cl100k},
+	{"gpt-3.5-turbo", cl100k},
+	{"text-embedding-", cl100k},
+	{"llama3", cl100k}, // a tiktoken-based 128k vocabulary
+	{"qwen", cl100k},
+	{"llama", ratioEstimate{charsPerToken: 3.5}}, // 32k SentencePiece
+	{"mistral", ratioEstimate{charsPerToken: 3.6}},
+	{"phi3", ratioEstimate{charsPerToken: 3.5}},
+	{"gemma", ratioEstimate{charsPerToken: 4.2}},
+	{"nomic-embed-text", ratioEstimate{charsPerToken: 4.2}},
+}
+
+// TokenizerForModel returns the estimate calibrated for a model's family, or
+// the four-characters-per-token rule for models it doesn't know.
+func TokenizerForModel(model string) Tokenizer {
+	model = strings.ToLower(model)
+	for _, t := range tokenizers {
+		if strings.HasPrefix(model, t.Prefix) {
+			return t.Tokenizer
+		}
+	}
+	return defaultTokenizer
+}
+
+// TokenizerFor returns the provider's own Tokenizer if it has one, or the
+// estimate for the model it sends requests to.
+func TokenizerFor(p Provider) Tokenizer {
+	if t, ok := p.(Tokenizer); ok {
+		return t
+	}
+	return TokenizerForModel(p.Model())
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CONTEXT WINDOWS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// contextWindows are the context sizes, in tokens, of models we commonly run.
+// Entries are matched by prefix, so "gpt-4o-mini" uses the "gpt-4o" window.
+var contextWindows = []struct {
+	Prefix string
+	Tokens int
+}{
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"llama3.1", 128000},
+	{"llama3", 8192},
+	{"mistral", 32768},
+}
+
+// defaultContextWindow is assumed for models not listed in contextWindows.
+const defaultContextWindow = 4096
+
+// ContextWindow returns the context size of a model in tokens.
+func ContextWindow(model string) int {
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.Prefix) {
+			return w.Tokens
+		}
+	}
+	return defaultContextWindow
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenizerForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  Tokenizer
+	}{
+		{"gpt-4o-mini", o200k},
+		{"gpt-4.1-nano", o200k},
+		{"gpt-4-turbo", cl100k},
+		{"gpt-3.5-turbo", cl100k},
+		{"text-embedding-3-small", cl100k},
+		{"llama3.1:8b", cl100k},
+		{"llama2:13b", ratioEstimate{charsPerToken: 3.5}},
+		{"Mistral:7b", ratioEstimate{charsPerToken: 3.6}},
+		{"some-new-model", defaultTokenizer},
+	}
+	for _, tt := range tests {
+		if got := TokenizerForModel(tt.model); got != tt.want {
+			t.Errorf("TokenizerForModel(%q) = %+v, want %+v", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("internationalisation ", 10))
+	tests := []struct {
+		name      string
+		tokenizer Tokenizer
+		text      string
+		want      int
+	}{
+		{"cl100k punctuation", cl100k, "Hello, world!", 4},
+		{"cl100k digits in threes", cl100k, "1234567", 3},
+		{"cl100k long words split", cl100k, long, 40},
+		{"o200k long words split less", o200k, long, 30},
+		{"sentencepiece ratio", ratioEstimate{charsPerToken: 3.5}, "Hello, world!", 4},
+		{"default", defaultTokenizer, "Hello, world!", 4},
+		{"empty", cl100k, "", 0},
+	}
+	for _, tt := range tests {
+		if got := tt.tokenizer.CountTokens(tt.text); got != tt.want {
+			t.Errorf("%s: CountTokens(%q) = %d, want %d", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+// countingFake is a provider that counts tokens itself.
+type countingFake struct {
+	*Fake
+}
+
+func (countingFake) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestTokenizerForPrefersTheProvider(t *testing.T) {
+	if got := TokenizerFor(NewFake("gpt-4o")); got != o200k {
+		t.Errorf("TokenizerFor(fake gpt-4o) = %+v, want the gpt-4o estimate", got)
+	}
+	p := countingFake{NewFake("gpt-4o")}
+	if got := TokenizerFor(p).CountTokens("one two three"); got != 3 {
+		t.Errorf("provider's own count = %d, want 3", got)
+	}
+}
@@ -1,31 +1,200 @@
 package main
 
 import (
+	"context"
 	"go-ai/config"
 	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/openai"
+	"go-ai/tenant"
 	"log"
 	"net/http"
+	"os"
+	"time"
 )
 
-func init() {
+const (
+	// chatPruneInterval is how often expired history is removed from a BoltDB or memory chat store.
+	chatPruneInterval = time.Hour
+	// minSessionSecretLength keeps session tokens from being signed with a guessable key.
+	minSessionSecretLength = 32
+)
+
+// setup loads the configuration and connects the databases the server and
+// commands run against. It runs from main rather than init, so tests of the
+// handlers don't need a configured environment.
+func setup() {
 	// Load environment variables
 	config.LoadEnv()
 
-	// Initialize databases
-	db.InitMongo()
+	// Load the portfolios this server hosts
+	if err := tenant.Load(); err != nil {
+		log.Fatalf("❌ Invalid tenant config: %v", err)
+	}
+	log.Printf("✅ Serving %d tenant(s)", len(tenant.All()))
+
+	// Visitors' session cookies are signed with this
+	if len(config.GetSessionSecret()) < minSessionSecretLength {
+		log.Fatalf("❌ SESSION_SECRET must be at least %d characters", minSessionSecretLength)
+	}
+
+	// Fail fast on a misconfigured LLM provider
+	if err := llm.ValidateConfig(); err != nil {
+		log.Fatalf("❌ Invalid LLM provider config: %v", err)
+	}
+
+	// Initialize databases; MongoDB only holds chat history, and a fixture
+	// stands in for Neo4j
+	if config.GetChatStore() == "mongo" {
+		db.InitMongo()
+
+		// Paging through long histories relies on these; chats work without them
+		for _, t := range tenant.All() {
+			ctx := tenant.NewContext(context.Background(), t)
+			if err := db.EnsureChatIndexes(ctx); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+			if err := db.EnsureChatRetention(ctx, chatRetention()); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		}
+	}
+	if config.GetResumeFixture() != "" {
+		return
+	}
 	db.InitNeo4j()
 
-	// Load graph schema once at startup
-	if err := db.LoadGraphSchemaOnce(); err != nil {
-		log.Fatalf("❌ Failed to load graph schema: %v", err)
+	for _, t := range tenant.All() {
+		ctx := tenant.NewContext(context.Background(), t)
+
+		// Load each tenant's graph schema once at startup
+		if err := db.LoadGraphSchemaOnce(ctx); err != nil {
+			log.Fatalf("❌ Failed to load graph schema for tenant %s: %v", t.ID, err)
+		}
+		log.Printf("✅ Graph schema loaded for tenant %s", t.ID)
+
+		// Keyword retrieval degrades gracefully, so a missing index is only a warning
+		if err := db.EnsureFullTextIndex(ctx); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
 	}
-	log.Println("✅ Graph schema loaded")
 }
 
 func main() {
+	// Copying chat history only needs the chat stores, not the server's setup
+	if len(os.Args) > 1 && os.Args[1] == "migrate-chats" {
+		runMigrateChats(os.Args[2:])
+		return
+	}
+
+	setup()
+
+	if len(os.Args) > 1 {
+		if config.GetResumeFixture() != "" {
+			log.Fatal("❌ Commands work on Neo4j; unset RESUME_FIXTURE to run them")
+		}
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	s := newServer()
+
+	// Keep embeddings fresh in the background; unchanged nodes are skipped
+	if config.GetSemanticRetrievalEnabled() && s.editable {
+		go func() {
+			for _, t := range tenant.All() {
+				report, err := openai.IndexEmbeddings(tenant.NewContext(context.Background(), t))
+				if err != nil {
+					log.Printf("⚠️  Embedding index failed for tenant %s: %v", t.ID, err)
+					continue
+				}
+				log.Printf("✅ Embedded %d of %d nodes for tenant %s", report.Embedded, report.Total, t.ID)
+			}
+		}()
+	}
+
 	port := config.GetServerPort()
 	addr := "0.0.0.0" + port
 
         log.Printf("✅ Server started on http://%s\n", addr)
-        log.Fatal(http.ListenAndServe(addr, RegisterRoutes()))
+        log.Fatal(http.ListenAndServe(addr, RegisterRoutes(s)))
+}
+
+// newServer wires the handlers to Neo4j, or to an in-memory graph when
+// RESUME_FIXTURE names an export to serve instead, and to the chat store
+// CHAT_STORE selects.
+func newServer() *server {
+	chats := newChatStore()
+	fixture := config.GetResumeFixture()
+	if fixture == "" {
+		return &server{
+			assistant: openai.NewAssistant(db.Neo4jRepository{}, chats),
+			editable:  true,
+		}
+	}
+
+	repo, err := db.LoadMemoryRepository(fixture)
+	if err != nil {
+		log.Fatalf("❌ Failed to load resume fixture: %v", err)
+	}
+	log.Printf("✅ Serving resume fixture %s from memory", fixture)
+	return &server{assistant: openai.NewAssistant(repo, chats)}
+}
+
+// newChatStore opens the chat history store CHAT_STORE selects.
+func newChatStore() db.ChatStore {
+	switch store := config.GetChatStore(); store {
+	case "mongo":
+		return db.MongoChatStore{}
+	case "bolt":
+		chats, err := db.OpenBoltChatStore(config.GetChatStoreFile())
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Chat history stored in %s", config.GetChatStoreFile())
+		if retention := chatRetention(); retention > 0 {
+			go pruneChats(chats, retention)
+		}
+		return chats
+	case "memory":
+		log.Println("⚠️  Chat history is kept in memory and lost on restart")
+		chats := db.NewMemoryChatStore()
+		if retention := chatRetention(); retention > 0 {
+			go pruneChats(chats, retention)
+		}
+		return chats
+	default:
+		log.Fatalf("❌ Unknown CHAT_STORE %q (use mongo, bolt or memory)", store)
+		return nil
+	}
+}
+
+// chatRetention is how long chat history is kept; zero keeps it forever.
+func chatRetention() time.Duration {
+	return time.Duration(config.GetChatRetentionDays()) * 24 * time.Hour
+}
+
+// chatPruner is a chat store without TTL indexes, which deletes expired
+// history when asked to.
+type chatPruner interface {
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// pruneChats expires chat history older than retention from a BoltDB or
+// memory store now and then every chatPruneInterval.
+func pruneChats(chats chatPruner, retention time.Duration) {
+	ticker := time.NewTicker(chatPruneInterval)
+	defer ticker.Stop()
+	for {
+		for _, t := range tenant.All() {
+			ctx := tenant.NewContext(context.Background(), t)
+			pruned, err := chats.Prune(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("[WARN] Failed to prune chat history for tenant %s: %v", t.ID, err)
+			} else if pruned > 0 {
+				log.Printf("🧹 Pruned %d expired messages for tenant %s", pruned, t.ID)
+			}
+		}
+		<-ticker.C
+	}
 }
@@ -0,0 +1,337 @@
+package ollama
+
+import (
+	"context"
+	"go-ai/config"
+	"go-ai/db"
+	"go-ai/tenant"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entityKind is one category of known graph values the fast path matches on.
+type entityKind struct {
+	Filter string // FilterClause.On used for a match
+	Target string // node type a match points at
+}
+
+var (
+	projectEntity = entityKind{Filter: "Name", Target: "Project"}
+	companyEntity = entityKind{Filter: "Company", Target: "WorkExperience"}
+	skillEntity   = entityKind{Filter: "Skill", Target: "Project"}
+	tagEntity     = entityKind{Filter: "Tag", Target: "Project"}
+	hobbyEntity   = entityKind{Filter: "Name", Target: "Hobby"}
+)
+
+// typeKeywords map words in the question to the node type they ask about.
+var typeKeywords = map[string]string{
+	"project":     "Project",
+	"projects":    "Project",
+	"built":       "Project",
+	"job":         "WorkExperience",
+	"jobs":        "WorkExperience",
+	"work":        "WorkExperience",
+	"worked":      "WorkExperience",
+	"experience":  "WorkExperience",
+	"internship":  "WorkExperience",
+	"internships": "WorkExperience",
+	"role":        "WorkExperience",
+	"roles":       "WorkExperience",
+	"school":      "Education",
+	"degree":      "Education",
+	"university":  "Education",
+	"study":       "Education",
+	"studied":     "Education",
+	"education":   "Education",
+	"hobby":       "Hobby",
+	"hobbies":     "Hobby",
+	"skills":      "Skill",
+	"stack":       "Skill",
+}
+
+// Words that change how filters combine; the LLM handles those questions.
+var (
+	negationWords    = map[string]bool{"not": true, "without": true, "except": true, "excluding": true, "dont": true, "doesnt": true, "didnt": true, "never": true}
+	disjunctionWords = map[string]bool{"or": true, "either": true}
+)
+
+// Confidence levels reported by the fast path.
+const (
+	confidenceExact     = 0.9 // every match points at one node type
+	confidenceAmbiguous = 0.8 // a name matched several kinds, searched broadly
+	confidenceMixed     = 0.6 // matches disagree about the target node type
+	confidenceTypeOnly  = 0.5 // node type named but no known values
+	confidenceModifier  = 0.3 // negation or "or" in the question
+)
+
+var nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N}+#]+`)
+
+// vocabularyCache holds each tenant's vocabulary by tenant ID.
+type vocabularyCache struct {
+	sync.Mutex
+	byTenant map[string]vocabulary
+}
+
+// vocabulary is a tenant's known entity names, keyed by normalised name, and
+// when they were loaded.
+type vocabulary struct {
+	entries  map[string][]entityMatch
+	loadedAt time.Time
+}
+
+type entityMatch struct {
+	Name string
+	Kind entityKind
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// FAST PATH
+// ─────────────────────────────────────────────────────────────────────────────
+
+// PlanFromRules builds a plan without the LLM by matching the question against
+// known project names, companies, skills, tags and hobbies. It returns the
+// plan with a confidence between 0 and 1; callers fall back to the LLM planner
+// when it is too low.
+func (p *Planner) PlanFromRules(ctx context.Context, userInput string) (GraphQueryPlan, float64) {
+	vocab, err := p.loadVocabulary(ctx)
+	if err != nil {
+		log.Printf("⚠️ Fast-path vocabulary unavailable: %v", err)
+		return GraphQueryPlan{}, 0
+	}
+
+	words := strings.Fields(normalizeEntity(userInput))
+	padded := " " + strings.Join(words, " ") + " "
+
+	var matches []entityMatch
+	for key, entries := range vocab {
+		if strings.Contains(padded, " "+key+" ") {
+			matches = append(matches, entries...)
+		}
+	}
+	matches = dropShadowedMatches(matches)
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+
+	typeTargets := map[string]bool{}
+	hasModifier := false
+	for _, w := range words {
+		if label, ok := typeKeywords[w]; ok {
+			typeTargets[label] = true
+		}
+		if negationWords[w] || disjunctionWords[w] {
+			hasModifier = true
+		}
+	}
+
+	plan := GraphQueryPlan{RawInput: userInput}
+	switch {
+	case len(matches) == 0 && len(typeTargets) == 0:
+		return plan, 0
+	case len(matches) == 0:
+		plan.TargetNodes = sortedLabels(typeTargets)
+		plan.Reasoning = "Question names node types but no known values"
+		return plan, confidenceTypeOnly
+	}
+
+	confidence := confidenceExact
+	targets := map[string]bool{}
+	byName := map[string][]entityMatch{}
+	for _, m := range matches {
+		byName[m.Name] = append(byName[m.Name], m)
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var reasons []string
+	for _, name := range names {
+		group := byName[name]
+		if len(group) > 1 {
+			// e.g. a project and a company share a name: search both broadly
+			for _, m := range group {
+				targets[m.Kind.Target] = true
+			}
+			confidence = min(confidence, confidenceAmbiguous)
+			reasons = append(reasons, name+" matches several node types")
+			continue
+		}
+
+		m := group[0]
+		target := m.Kind.Target
+		if (m.Kind == skillEntity || m.Kind == tagEntity) && len(typeTargets) == 1 {
+			// "jobs using Go": the named type wins over the default target
+			target = sortedLabels(typeTargets)[0]
+		}
+		targets[target] = true
+		plan.Filters = append(plan.Filters, db.FilterClause{
+			On:       m.Kind.Filter,
+			Value:    m.Name,
+			Relation: defaultRelations[m.Kind.Filter],
+		})
+		reasons = append(reasons, m.Kind.Filter+" "+m.Name)
+	}
+
+	if len(targets) > 1 && len(plan.Filters) > 0 {
+		confidence = min(confidence, confidenceMixed)
+	}
+	if hasModifier {
+		confidence = min(confidence, confidenceModifier)
+	}
+
+	plan.TargetNodes = sortedLabels(targets)
+	plan.Reasoning = "Matched known values: " + strings.Join(reasons, ", ")
+	return plan, confidence
+}
+
+// ResetVocabulary forces the next fast-path lookup and entity resolution to
+// reload names, e.g. after the graph content changed.
+func (p *Planner) ResetVocabulary() {
+	p.vocabulary.Lock()
+	p.vocabulary.byTenant = nil
+	p.vocabulary.Unlock()
+
+	p.ResetEntityNames()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// loadVocabulary fetches every known entity name of the tenant ctx is scoped
+// to and caches it for PLANNER_CACHE_TTL_SECONDS.
+func (p *Planner) loadVocabulary(ctx context.Context) (map[string][]entityMatch, error) {
+	p.vocabulary.Lock()
+	defer p.vocabulary.Unlock()
+	tenantID := tenant.FromContext(ctx).ID
+	if v, ok := p.vocabulary.byTenant[tenantID]; ok && time.Since(v.loadedAt) < config.GetPlannerCacheTTL() {
+		return v.entries, nil
+	}
+
+	entries := map[string][]entityMatch{}
+	addAs := func(kind entityKind, name string, spellings ...string) {
+		for _, spelling := range spellings {
+			key := normalizeEntity(spelling)
+			if len(key) < 2 {
+				continue
+			}
+			entries[key] = append(entries[key], entityMatch{Name: name, Kind: kind})
+		}
+	}
+	add := func(kind entityKind, names ...string) {
+		for _, name := range names {
+			addAs(kind, name, name)
+		}
+	}
+
+	projects, err := p.Resume.ListProjectNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	add(projectEntity, projects...)
+
+	companies, err := p.Resume.ListWorkExperienceCompanies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	add(companyEntity, uniqueStrings(companies)...)
+
+	skills, err := p.Resume.GetAllSkillsSorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range skills {
+		add(skillEntity, s.Name)
+	}
+
+	tags, err := p.Resume.GetAllTagsSorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		add(tagEntity, t.Name)
+	}
+
+	hobbies, err := p.Resume.GetAllHobbies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hobbies {
+		add(hobbyEntity, h.Name)
+	}
+
+	// Aliases stored on the nodes, e.g. "golang" for Go
+	aliasKinds := map[string]entityKind{"Skill": skillEntity, "Tag": tagEntity, "Hobby": hobbyEntity}
+	for _, label := range db.AliasLabels {
+		names, err := p.Resume.ListEntityNames(ctx, label)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			addAs(aliasKinds[label], n.Name, n.Aliases...)
+		}
+	}
+
+	if p.vocabulary.byTenant == nil {
+		p.vocabulary.byTenant = map[string]vocabulary{}
+	}
+	p.vocabulary.byTenant[tenantID] = vocabulary{entries: entries, loadedAt: time.Now()}
+	log.Printf("✅ Fast-path vocabulary loaded (%d names)", len(entries))
+	return entries, nil
+}
+
+// normalizeEntity lowercases text, drops apostrophes and collapses other
+// punctuation to single spaces, keeping + and # so C++ and C# survive.
+func normalizeEntity(text string) string {
+	text = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(text))
+	return strings.TrimSpace(nonWordPattern.ReplaceAllString(text, " "))
+}
+
+// dropShadowedMatches removes matches whose name is part of a longer matched
+// name, so "Java" doesn't also fire for "JavaScript Game Jam".
+func dropShadowedMatches(matches []entityMatch) []entityMatch {
+	var kept []entityMatch
+	for _, m := range matches {
+		key := " " + normalizeEntity(m.Name) + " "
+		shadowed := false
+		for _, other := range matches {
+			otherKey := " " + normalizeEntity(other.Name) + " "
+			if len(otherKey) > len(key) && strings.Contains(otherKey, key) {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// sortedLabels returns the set's labels in queryableLabels order.
+func sortedLabels(set map[string]bool) []string {
+	var labels []string
+	for _, l := range queryableLabels {
+		if set[l] {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package ollama
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/tenant"
+)
+
+// fastPathFixture names Nimbus as both a project and a company, and has a
+// project whose name contains a skill.
+var fastPathFixture = db.Export{
+	Graph: db.ResumeGraph{
+		Person: &db.Person{ID: "me", Name: "Ada Example"},
+		Projects: []db.Project{
+			{ID: "atlas", Name: "Atlas"},
+			{ID: "jam", Name: "JavaScript Game Jam"},
+			{ID: "nimbus", Name: "Nimbus"},
+		},
+		WorkExperience: []db.WorkExperience{
+			{ID: "w1", Title: "Engineer", Company: "Initech"},
+			{ID: "w2", Title: "Intern", Company: "Nimbus"},
+		},
+		Skills:  []db.Skill{{Name: "Go"}, {Name: "Java"}, {Name: "React"}},
+		Tags:    []db.Tag{{Name: "Backend"}},
+		Hobbies: []db.Hobby{{Name: "Chess"}},
+	},
+	Aliases: map[string][]db.EntityName{"Skill": {{Name: "Go", Aliases: []string{"golang"}}}},
+}
+
+func TestPlanFromRules(t *testing.T) {
+	p := NewPlanner(db.NewMemoryRepository(fastPathFixture))
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+
+	tests := []struct {
+		question   string
+		confidence float64
+		targets    []string
+		filters    []db.FilterClause
+	}{
+		{"Tell me about Atlas", confidenceExact, []string{"Project"}, []db.FilterClause{{On: "Name", Value: "Atlas"}}},
+		{"What did you do at Initech?", confidenceExact, []string{"WorkExperience"}, []db.FilterClause{{On: "Company", Value: "Initech"}}},
+		{"Which projects use golang?", confidenceExact, []string{"Project"}, []db.FilterClause{{On: "Skill", Value: "Go", Relation: "HAS_SKILL"}}},
+		{"Which jobs used React?", confidenceExact, []string{"WorkExperience"}, []db.FilterClause{{On: "Skill", Value: "React", Relation: "HAS_SKILL"}}},
+		{"Tell me about the JavaScript Game Jam", confidenceExact, []string{"Project"}, []db.FilterClause{{On: "Name", Value: "JavaScript Game Jam"}}},
+		{"Do you play chess?", confidenceExact, []string{"Hobby"}, []db.FilterClause{{On: "Name", Value: "Chess"}}},
+		{"What is Nimbus?", confidenceAmbiguous, []string{"Project", "WorkExperience"}, nil},
+		{"Compare Atlas with your time at Initech", confidenceMixed, []string{"Project", "WorkExperience"}, []db.FilterClause{{On: "Name", Value: "Atlas"}, {On: "Company", Value: "Initech"}}},
+		{"What projects have you built?", confidenceTypeOnly, []string{"Project"}, nil},
+		{"Projects that don't use React", confidenceModifier, []string{"Project"}, []db.FilterClause{{On: "Skill", Value: "React", Relation: "HAS_SKILL"}}},
+		{"Backend or React projects", confidenceModifier, []string{"Project"}, []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}, {On: "Skill", Value: "React", Relation: "HAS_SKILL"}}},
+		{"What's the weather like?", 0, nil, nil},
+	}
+	for _, tt := range tests {
+		plan, confidence := p.PlanFromRules(ctx, tt.question)
+		if confidence != tt.confidence {
+			t.Errorf("%q: confidence %.1f, want %.1f", tt.question, confidence, tt.confidence)
+		}
+		if !slices.Equal(plan.TargetNodes, tt.targets) {
+			t.Errorf("%q: targets %v, want %v", tt.question, plan.TargetNodes, tt.targets)
+		}
+		if !slices.Equal(plan.Filters, tt.filters) {
+			t.Errorf("%q: filters %+v, want %+v", tt.question, plan.Filters, tt.filters)
+		}
+	}
+}
+
+func TestPlanGraphQueryFastPath(t *testing.T) {
+	t.Setenv("PLANNER_FASTPATH_ENABLED", "true")
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+
+	tests := []struct {
+		question      string
+		minConfidence string
+		planner       string
+	}{
+		{"Tell me about Atlas", "0.75", db.PlannerRules},
+		{"What is Nimbus?", "0.75", db.PlannerRules},
+		{"What is Nimbus?", "0.85", db.PlannerLLM},
+		{"Tell me about Atlas", "0.95", db.PlannerLLM},
+		{"Compare Atlas with your time at Initech", "0.75", db.PlannerLLM},
+		{"What projects have you built?", "0.75", db.PlannerLLM},
+		{"Projects that don't use React", "0.75", db.PlannerLLM},
+		{"What's the weather like?", "0", db.PlannerLLM},
+	}
+	for _, tt := range tests {
+		t.Setenv("PLANNER_FASTPATH_MIN_CONFIDENCE", tt.minConfidence)
+		planner := usePlanner(t, llm.FakeRule{Match: "QUESTION:", Reply: `{"target_nodes": ["Project"], "filters": []}`})
+		p := NewPlanner(db.NewMemoryRepository(fastPathFixture))
+
+		plan, err := p.PlanGraphQuery(ctx, tt.question, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.Trace.Planner != tt.planner {
+			t.Errorf("%q at %s: planned by %s, want %s", tt.question, tt.minConfidence, plan.Trace.Planner, tt.planner)
+		}
+		if asked := len(planner.Calls) > 0; asked != (tt.planner == db.PlannerLLM) {
+			t.Errorf("%q at %s: LLM planner asked: %t", tt.question, tt.minConfidence, asked)
+		}
+		if tt.planner == db.PlannerRules && (plan.Trace.Outcome != db.PlanValid || plan.Trace.Confidence < 0.75) {
+			t.Errorf("%q: fast path trace = %+v", tt.question, plan.Trace)
+		}
+	}
+}
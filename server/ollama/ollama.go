@@ -1,14 +1,13 @@
 package ollama
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"go-ai/config"
 	"go-ai/db"
-	"io"
+	"go-ai/llm"
 	"log"
-	"net/http"
 	"strings"
 )
 
@@ -16,55 +15,42 @@ import (
 // TYPES
 // ─────────────────────────────────────────────────────────────────────────────
 
-type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-}
-
-type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-}
-
 type GraphQueryPlan struct {
 	TargetNodes []string          `json:"target_nodes"`
 	Filters     []db.FilterClause `json:"filters"`
+	Reasoning   string            `json:"reasoning"`
 	RawInput    string            `json:"raw_input"`
+	Trace       db.PlanTrace      `json:"-"`
 }
 
-// ─────────────────────────────────────────────────────────────────────────────
-// API WRAPPER
-// ─────────────────────────────────────────────────────────────────────────────
+// Planner turns questions into graph query plans against the resume graph it
+// reads from, caching the names it matches questions against per tenant for
+// PLANNER_CACHE_TTL_SECONDS.
+type Planner struct {
+	Resume db.ResumeRepository
 
-// SendPrompt sends a prompt to the local Ollama server and returns the string response.
-func SendPrompt(prompt string) (string, error) {
-	reqBody, err := json.Marshal(OllamaRequest{
-		Model:  "llama3",
-		Prompt: prompt,
-		Stream: false,
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal Ollama request: %w", err)
-	}
+	vocabulary  vocabularyCache
+	entityNames entityNameCache
+}
 
-	resp, err := http.Post(config.GetOllamaURI()+"/api/generate", "application/json", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("Ollama POST request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// NewPlanner returns a Planner reading the resume graph from resume.
+func NewPlanner(resume db.ResumeRepository) *Planner {
+	return &Planner{Resume: resume}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("Failed to read Ollama response body: %w", err)
-	}
+// ─────────────────────────────────────────────────────────────────────────────
+// API WRAPPER
+// ─────────────────────────────────────────────────────────────────────────────
 
-	var result OllamaResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("Unmarshal failed: %w\nRaw body: %s", err, string(body))
-	}
+// SendPrompt sends a prompt to the configured planner provider (Ollama llama3 by
+// default) and returns the string response.
+func SendPrompt(ctx context.Context, prompt string) (string, error) {
+	return llm.ForRole(ctx, llm.RolePlanner).Generate(ctx, prompt)
+}
 
-	return result.Response, nil
+// SendStructuredPrompt is SendPrompt with output constrained to the JSON schema.
+func SendStructuredPrompt(ctx context.Context, prompt string, schema llm.JSONSchema) (string, error) {
+	return llm.ForRole(ctx, llm.RolePlanner).GenerateJSON(ctx, prompt, schema)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -72,12 +58,22 @@ func SendPrompt(prompt string) (string, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // BuildGraphPlannerPrompt dynamically creates a schema-aware graph planning prompt.
-func BuildGraphPlannerPrompt(schema db.GraphSchema, userQuery string) string {
+// conversation is an optional transcript of recent turns used to resolve references.
+// owner is the name of the person the resume belongs to.
+func BuildGraphPlannerPrompt(schema db.GraphSchema, userQuery, conversation, owner string) string {
 	nodeSection := strings.Join(schema.NodeLabels, "\n- ")
 	relSection := strings.Join(schema.Relationships, "\n- ")
 
+	conversationSection := ""
+	if conversation != "" {
+		conversationSection = fmt.Sprintf(`
+RECENT CONVERSATION (use it to resolve references like "it" or "that project"):
+%s
+`, conversation)
+	}
+
 	return fmt.Sprintf(`
-You are a graph planner for a chatbot that answers questions about Gabriella's resume and experience.
+You are a graph planner for a chatbot that answers questions about %s's resume and experience.
 
 The knowledge graph includes the following:
 
@@ -104,7 +100,7 @@ Given a natural language question, return a graph query plan using this format:
 
 GUIDELINES:
 - Use only valid node and relationship types from the schema above.
-- Do not return "Person" unless the user is directly asking about Gabriella herself.
+- Do not return "Person" unless the user is directly asking about %s as a person.
 - Do not include filters with "value": null or "*".
 - Output only a single JSON object. No markdown, no commentary, no alternatives.
 - If the query references something ambiguous (like "Val-T" or "Hyperpad"), include both "Project" and "WorkExperience" with no filters.
@@ -115,33 +111,167 @@ GUIDELINES:
   - HAS_SKILL
   - HAS_HOBBY
 - Ignore or remap any other relationship types to the above.
+- Filters are combined with AND by default. Add "op": "or" to a filter when any of several values may match, or "op": "not" to exclude a value (e.g. "projects that don't use React").
 - If unsure, return broad results with empty filters.
-
+%s
 QUESTION:
 %s
-`, nodeSection, relSection, userQuery)
+`, owner, nodeSection, relSection, owner, conversationSection, userQuery)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
 // PLANNER LOGIC
 // ─────────────────────────────────────────────────────────────────────────────
 
-// PlanGraphQuery builds a structured graph query plan from the user's input.
-func PlanGraphQuery(userInput string) (GraphQueryPlan, error) {
-	prompt := BuildGraphPlannerPrompt(db.CachedSchema, userInput)
+// PlanGraphQuery builds a structured graph query plan from the user's input and
+// an optional transcript of the recent conversation. Plans are validated against
+// the cached schema; what the synonym tables can't fix is sent back to the model
+// for up to maxRepairAttempts repairs before the invalid parts are dropped.
+func (p *Planner) PlanGraphQuery(ctx context.Context, userInput, conversation string) (GraphQueryPlan, error) {
+	if config.GetPlannerFastPathEnabled() {
+		if plan, ok := p.planFastPath(ctx, userInput); ok {
+			return plan, nil
+		}
+	}
+
+	graphSchema := p.Resume.Schema(ctx)
+	owner := p.ownerName(ctx)
+	prompt := BuildGraphPlannerPrompt(graphSchema, userInput, conversation, owner)
+
+	structured := config.GetPlannerStructuredOutput()
+	schema := PlanJSONSchema(graphSchema)
+
+	var trace db.PlanTrace
+	var plan GraphQueryPlan
+	var problems []string
+	currentPrompt := prompt
 
-	rawResp, err := SendPrompt(prompt)
-	if err != nil {
-		return GraphQueryPlan{}, err
+	for attempt := 0; attempt <= maxRepairAttempts; attempt++ {
+		trace.Attempts = attempt + 1
+
+		var rawResp string
+		var err error
+		if structured {
+			rawResp, err = SendStructuredPrompt(ctx, currentPrompt, schema)
+		} else {
+			rawResp, err = SendPrompt(ctx, currentPrompt)
+		}
+		if err != nil {
+			return GraphQueryPlan{}, err
+		}
+
+		var parseErr error
+		plan, parseErr = ParseIntentResponse(rawResp)
+		if parseErr != nil {
+			problems = []string{"response was not a valid JSON plan: " + parseErr.Error()}
+		} else {
+			remapped := remapOwnerName(&plan, owner)
+			var more []string
+			more, problems = ValidatePlan(&plan, graphSchema)
+			remapped = append(remapped, more...)
+			trace.Remapped = append(trace.Remapped, remapped...)
+		}
+		trace.Errors = append(trace.Errors, problems...)
+
+		if len(problems) == 0 {
+			break
+		}
+		log.Printf("plan attempt %d invalid: %v", trace.Attempts, problems)
+		currentPrompt = buildRepairPrompt(prompt, rawResp, problems)
 	}
 
-	parsed, err := ParseIntentResponse(rawResp)
-	if err != nil {
-		return GraphQueryPlan{}, err
+	switch {
+	case len(problems) > 0 && plan.TargetNodes == nil && plan.Filters == nil:
+		return GraphQueryPlan{}, fmt.Errorf("planner returned no usable plan after %d attempts: %v", trace.Attempts, problems)
+	case len(problems) > 0:
+		dropInvalid(&plan, graphSchema)
+		trace.Outcome = db.PlanDegraded
+	case trace.Attempts > 1:
+		trace.Outcome = db.PlanRepaired
+	case len(trace.Remapped) > 0:
+		trace.Outcome = db.PlanRemapped
+	default:
+		trace.Outcome = db.PlanValid
 	}
+	log.Printf("plan outcome: %s after %d attempt(s), remapped=%v", trace.Outcome, trace.Attempts, trace.Remapped)
+	log.Println("plan reasoning:", plan.Reasoning)
+	trace.Planner = db.PlannerLLM
+	trace.Reasoning = plan.Reasoning
+	trace.Resolutions = p.ResolveFilterValues(ctx, &plan)
 
-	parsed.RawInput = userInput
-	return parsed, nil
+	plan.RawInput = userInput
+	plan.Trace = trace
+	return plan, nil
+}
+
+// planFastPath returns the rule-based plan when it is confident enough and
+// valid against the schema. A question that matched nothing never is, even
+// with the threshold at 0.
+func (p *Planner) planFastPath(ctx context.Context, userInput string) (GraphQueryPlan, bool) {
+	plan, confidence := p.PlanFromRules(ctx, userInput)
+	if confidence == 0 || confidence < config.GetPlannerFastPathMinConfidence() {
+		if confidence > 0 {
+			log.Printf("fast path confidence %.2f too low, asking the LLM planner", confidence)
+		}
+		return GraphQueryPlan{}, false
+	}
+
+	remapped, problems := ValidatePlan(&plan, p.Resume.Schema(ctx))
+	if len(problems) > 0 {
+		log.Printf("fast path plan invalid, asking the LLM planner: %v", problems)
+		return GraphQueryPlan{}, false
+	}
+
+	plan.Trace = db.PlanTrace{
+		Outcome:    db.PlanValid,
+		Remapped:   remapped,
+		Planner:    db.PlannerRules,
+		Confidence: confidence,
+		Reasoning:  plan.Reasoning,
+	}
+	plan.Trace.Resolutions = p.ResolveFilterValues(ctx, &plan)
+	log.Printf("plan outcome: fast path (confidence %.2f) targets=%v filters=%+v", confidence, plan.TargetNodes, plan.Filters)
+	return plan, true
+}
+
+// ownerName returns the Person node's name, or a neutral stand-in.
+func (p *Planner) ownerName(ctx context.Context) string {
+	person, err := p.Resume.GetPerson(ctx)
+	if err != nil || person.Name == "" {
+		return "the portfolio owner"
+	}
+	return person.Name
+}
+
+// remapOwnerName maps target nodes naming the owner (e.g. "Gabriella") to Person.
+func remapOwnerName(plan *GraphQueryPlan, owner string) []string {
+	names := map[string]bool{strings.ToLower(owner): true}
+	if first, _, ok := strings.Cut(owner, " "); ok {
+		names[strings.ToLower(first)] = true
+	}
+
+	var remapped []string
+	for i, t := range plan.TargetNodes {
+		if names[strings.ToLower(strings.TrimSpace(t))] {
+			plan.TargetNodes[i] = "Person"
+			remapped = append(remapped, fmt.Sprintf("node %q → %q", t, "Person"))
+		}
+	}
+	return remapped
+}
+
+// buildRepairPrompt asks the model to fix its previous plan given the errors.
+func buildRepairPrompt(originalPrompt, previous string, problems []string) string {
+	return fmt.Sprintf(`%s
+
+Your previous plan was invalid:
+- %s
+
+Previous output:
+%s
+
+Return a corrected plan as a single JSON object. Use only the node types, filters and relationships listed above.
+`, originalPrompt, strings.Join(problems, "\n- "), previous)
 }
 
 // ParseIntentResponse extracts the JSON graph plan from a raw LLM response.
@@ -0,0 +1,191 @@
+package ollama
+
+import (
+	"context"
+	"go-ai/config"
+	"go-ai/db"
+	"go-ai/tenant"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// resolvableFilters maps the filters whose values name a node to that node's label.
+var resolvableFilters = map[string]string{
+	"Tag":   "Tag",
+	"Skill": "Skill",
+	"Hobby": "Hobby",
+}
+
+// nameSuffixes are dropped when comparing names, so "react.js" meets "React",
+// but only after a separator or from a long enough name: "Erlang" keeps its
+// "lang", and "golang" meets "Go" through an alias instead.
+var nameSuffixes = []string{"js", "lang"}
+
+// minSuffixedKey is the shortest key left after dropping a suffix that isn't
+// set off by a separator, as in "reactjs".
+const minSuffixedKey = 4
+
+// minFuzzyKey is the shortest key matched fuzzily. Shorter ones ("c", "ml")
+// say too little to guess from and must match a name or alias exactly.
+const minFuzzyKey = 3
+
+// minEditKey is the shortest key scored by edit distance. Below it one edit
+// makes another name ("css" and "scss").
+const minEditKey = 5
+
+// entityNameCache holds canonical names and aliases per tenant and label.
+type entityNameCache struct {
+	sync.Mutex
+	byKey map[string]cachedNames
+}
+
+// cachedNames is one label's names and when they were loaded.
+type cachedNames struct {
+	names    []db.EntityName
+	loadedAt time.Time
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// RESOLUTION
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ResolveFilterValues rewrites Tag, Skill and Hobby filter values to the
+// canonical name of the closest node, using exact names, the aliases stored
+// on the nodes and fuzzy matching, in that order. Values that score below
+// RESOLVER_MIN_SCORE are left as they are. Every attempt is returned so it
+// can be recorded in the plan trace.
+func (p *Planner) ResolveFilterValues(ctx context.Context, plan *GraphQueryPlan) []db.Resolution {
+	minScore := config.GetResolverMinScore()
+	var resolutions []db.Resolution
+
+	for i, f := range plan.Filters {
+		label, ok := resolvableFilters[f.On]
+		if !ok || strings.TrimSpace(f.Value) == "" {
+			continue
+		}
+		names, err := p.namesFor(ctx, label)
+		if err != nil {
+			log.Printf("⚠️ Could not load %s names for resolution: %v", label, err)
+			continue
+		}
+
+		r := resolveName(f.Value, names)
+		r.On = f.On
+		if r.Score < minScore {
+			r.Resolved = ""
+			r.Via = db.ResolvedNone
+		} else if r.Resolved != f.Value {
+			log.Printf("resolved %s %q → %q (%s, %.2f)", f.On, f.Value, r.Resolved, r.Via, r.Score)
+			plan.Filters[i].Value = r.Resolved
+		}
+		resolutions = append(resolutions, r)
+	}
+	return resolutions
+}
+
+// ResetEntityNames drops the cached names so the next resolution reloads them.
+func (p *Planner) ResetEntityNames() {
+	p.entityNames.Lock()
+	defer p.entityNames.Unlock()
+	p.entityNames.byKey = nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// resolveName finds the best-scoring canonical name for value.
+func resolveName(value string, names []db.EntityName) db.Resolution {
+	best := db.Resolution{Value: value, Via: db.ResolvedNone}
+	key := nameKey(value)
+
+	for _, n := range names {
+		if strings.EqualFold(n.Name, value) {
+			return db.Resolution{Value: value, Resolved: n.Name, Via: db.ResolvedExact, Score: 1}
+		}
+		for _, alias := range n.Aliases {
+			if strings.EqualFold(alias, value) || nameKey(alias) == key {
+				return db.Resolution{Value: value, Resolved: n.Name, Via: db.ResolvedAlias, Score: 1}
+			}
+		}
+
+		for _, candidate := range append([]string{n.Name}, n.Aliases...) {
+			if score := similarity(key, nameKey(candidate)); score > best.Score {
+				best.Resolved = n.Name
+				best.Via = db.ResolvedFuzzy
+				best.Score = score
+			}
+		}
+	}
+	return best
+}
+
+// similarity scores two name keys between 0 and 1: the better of their edit
+// distance ratio and, when one starts the other (e.g. "postgres" and
+// "postgresql"), how much of the longer one it covers. Keys shorter than
+// minFuzzyKey score 0 against anything but themselves.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) < minFuzzyKey {
+		return 0
+	}
+
+	var score float64
+	if len(long) >= minEditKey {
+		score = 1 - float64(fuzzy.LevenshteinDistance(a, b))/float64(len(long))
+	}
+	if strings.HasPrefix(long, short) {
+		score = max(score, 0.5+0.5*float64(len(short))/float64(len(long)))
+	}
+	return score
+}
+
+// nameKey reduces a name to lowercase letters, digits, + and #, minus a
+// trailing "js" or "lang" that follows a separator or leaves at least
+// minSuffixedKey characters.
+func nameKey(name string) string {
+	normalized := normalizeEntity(name)
+	key := strings.ReplaceAll(normalized, " ", "")
+	for _, suffix := range nameSuffixes {
+		trimmed, ok := strings.CutSuffix(key, suffix)
+		if ok && trimmed != "" && (strings.HasSuffix(normalized, " "+suffix) || len(trimmed) >= minSuffixedKey) {
+			return trimmed
+		}
+	}
+	return key
+}
+
+// namesFor returns the tenant's cached names for a label, loading them on
+// first use and again once they are older than PLANNER_CACHE_TTL_SECONDS.
+func (p *Planner) namesFor(ctx context.Context, label string) ([]db.EntityName, error) {
+	p.entityNames.Lock()
+	defer p.entityNames.Unlock()
+	key := tenant.FromContext(ctx).ID + "/" + label
+	if cached, ok := p.entityNames.byKey[key]; ok && time.Since(cached.loadedAt) < config.GetPlannerCacheTTL() {
+		return cached.names, nil
+	}
+
+	names, err := p.Resume.ListEntityNames(ctx, label)
+	if err != nil {
+		return nil, err
+	}
+	if p.entityNames.byKey == nil {
+		p.entityNames.byKey = map[string]cachedNames{}
+	}
+	p.entityNames.byKey[key] = cachedNames{names: names, loadedAt: time.Now()}
+	return names, nil
+}
@@ -0,0 +1,80 @@
+package ollama
+
+import (
+	"context"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/tenant"
+)
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"React", "react"},
+		{"react.js", "react"},
+		{"ReactJS", "react"},
+		{"Vue.js", "vue"},
+		{"vuejs", "vuejs"},
+		{"go-lang", "go"},
+		{"golang", "golang"},
+		{"Erlang", "erlang"},
+		{"C#", "c#"},
+		{"Node JS", "node"},
+	}
+	for _, tt := range tests {
+		if got := nameKey(tt.name); got != tt.want {
+			t.Errorf("nameKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFilterValues(t *testing.T) {
+	t.Setenv("RESOLVER_MIN_SCORE", "0.75")
+	skills := []string{"Go", "React", "HTML", "C#", "Erlang", "PostgreSQL", "Vue", "SCSS", "Kubernetes"}
+	export := db.Export{Aliases: map[string][]db.EntityName{"Skill": {{Name: "Go", Aliases: []string{"golang"}}}}}
+	for _, name := range skills {
+		export.Graph.Skills = append(export.Graph.Skills, db.Skill{Name: name})
+	}
+	p := NewPlanner(db.NewMemoryRepository(export))
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+
+	tests := []struct {
+		value string
+		want  string // the resolved value, or "" when left as it was
+		via   string
+	}{
+		{"react", "React", db.ResolvedExact},
+		{"golang", "Go", db.ResolvedAlias},
+		{"go lang", "Go", db.ResolvedFuzzy},
+		{"react.js", "React", db.ResolvedFuzzy},
+		{"vue.js", "Vue", db.ResolvedFuzzy},
+		{"postgres", "PostgreSQL", db.ResolvedFuzzy},
+		{"kubernets", "Kubernetes", db.ResolvedFuzzy},
+		{"ML", "", db.ResolvedNone},
+		{"c", "", db.ResolvedNone},
+		{"lang", "", db.ResolvedNone},
+		{"er", "", db.ResolvedNone},
+		{"css", "", db.ResolvedNone},
+		{"Java", "", db.ResolvedNone},
+	}
+	for _, tt := range tests {
+		plan := GraphQueryPlan{Filters: []db.FilterClause{{On: "Skill", Value: tt.value}}}
+		resolutions := p.ResolveFilterValues(ctx, &plan)
+		if len(resolutions) != 1 {
+			t.Fatalf("%q: %d resolutions, want 1", tt.value, len(resolutions))
+		}
+		r := resolutions[0]
+		if r.Resolved != tt.want || r.Via != tt.via {
+			t.Errorf("%q resolved to %q via %s (%.2f), want %q via %s", tt.value, r.Resolved, r.Via, r.Score, tt.want, tt.via)
+		}
+		wantValue := tt.want
+		if wantValue == "" {
+			wantValue = tt.value
+		}
+		if plan.Filters[0].Value != wantValue {
+			t.Errorf("%q: filter value = %q, want %q", tt.value, plan.Filters[0].Value, wantValue)
+		}
+	}
+}
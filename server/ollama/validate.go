@@ -0,0 +1,257 @@
+package ollama
+
+import (
+	"fmt"
+	"go-ai/db"
+	"go-ai/llm"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxRepairAttempts bounds how often an invalid plan is sent back to the model.
+const maxRepairAttempts = 2
+
+// queryableLabels are the node types the context builder knows how to fetch.
+var queryableLabels = []string{"Project", "WorkExperience", "Education", "Hobby", "Skill", "Person"}
+
+// supportedFilters are the FilterClause.On values the query builder can apply.
+var supportedFilters = []string{"Tag", "Skill", "Hobby", "Name", "Company", "Institution", "Field"}
+
+// labelSynonyms maps common model inventions (lowercased) to real node labels.
+var labelSynonyms = map[string]string{
+	"job":        "WorkExperience",
+	"jobs":       "WorkExperience",
+	"work":       "WorkExperience",
+	"experience": "WorkExperience",
+	"employment": "WorkExperience",
+	"internship": "WorkExperience",
+	"role":       "WorkExperience",
+	"projects":   "Project",
+	"school":     "Education",
+	"degree":     "Education",
+	"university": "Education",
+	"interest":   "Hobby",
+	"hobbies":    "Hobby",
+	"skills":     "Skill",
+	"technology": "Skill",
+	"tech":       "Skill",
+	"language":   "Skill",
+	"framework":  "Skill",
+	"tool":       "Skill",
+	"person":     "Person",
+	"me":         "Person",
+	"bio":        "Person",
+	"about":      "Person",
+}
+
+// filterSynonyms maps invented filter fields (lowercased) to supported ones.
+var filterSynonyms = map[string]string{
+	"tags":       "Tag",
+	"category":   "Tag",
+	"topic":      "Tag",
+	"technology": "Skill",
+	"tech":       "Skill",
+	"language":   "Skill",
+	"framework":  "Skill",
+	"skills":     "Skill",
+	"interest":   "Hobby",
+	"title":      "Name",
+	"project":    "Name",
+	"employer":   "Company",
+	"workplace":  "Company",
+	"school":     "Institution",
+	"university": "Institution",
+	"major":      "Field",
+	"subject":    "Field",
+}
+
+// defaultRelations is the relation a filter implies when the model's is invalid.
+var defaultRelations = map[string]string{
+	"Tag":   "HAS_TAG",
+	"Skill": "HAS_SKILL",
+	"Hobby": "HAS_HOBBY",
+}
+
+var relationPattern = regexp.MustCompile(`\[:(\w+)\]`)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// VALIDATION
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ValidatePlan checks a plan against the schema and fixes what it can in place
+// using the synonym tables. It returns the remaps applied and the problems that
+// could not be fixed locally.
+func ValidatePlan(plan *GraphQueryPlan, schema db.GraphSchema) (remapped, problems []string) {
+	labels := knownLabels(schema)
+	relations := knownRelations(schema)
+
+	var targets []string
+	seen := map[string]bool{}
+	for _, raw := range plan.TargetNodes {
+		label, ok := canonical(raw, labels, labelSynonyms)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown node type %q (valid: %s)", raw, strings.Join(queryableLabels, ", ")))
+			targets = append(targets, raw)
+			continue
+		}
+		if label != raw {
+			remapped = append(remapped, fmt.Sprintf("node %q → %q", raw, label))
+		}
+		if !seen[label] {
+			seen[label] = true
+			targets = append(targets, label)
+		}
+	}
+	plan.TargetNodes = targets
+
+	filters := make(map[string]bool, len(supportedFilters))
+	for _, f := range supportedFilters {
+		filters[f] = true
+	}
+	for i := range plan.Filters {
+		f := &plan.Filters[i]
+		on, ok := canonical(f.On, filters, filterSynonyms)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unsupported filter field %q (valid: %s)", f.On, strings.Join(supportedFilters, ", ")))
+			continue
+		}
+		if on != f.On {
+			remapped = append(remapped, fmt.Sprintf("filter %q → %q", f.On, on))
+			f.On = on
+		}
+		if f.Relation != "" && !relations[f.Relation] {
+			remapped = append(remapped, fmt.Sprintf("relation %q → %q", f.Relation, defaultRelations[on]))
+			f.Relation = defaultRelations[on]
+		}
+	}
+
+	return remapped, problems
+}
+
+// dropInvalid removes the targets and filters ValidatePlan could not fix.
+func dropInvalid(plan *GraphQueryPlan, schema db.GraphSchema) {
+	labels := knownLabels(schema)
+	var targets []string
+	for _, t := range plan.TargetNodes {
+		if labels[t] {
+			targets = append(targets, t)
+		}
+	}
+	plan.TargetNodes = targets
+
+	var filters []db.FilterClause
+	for _, f := range plan.Filters {
+		for _, supported := range supportedFilters {
+			if f.On == supported {
+				filters = append(filters, f)
+				break
+			}
+		}
+	}
+	plan.Filters = filters
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// INTERNAL HELPERS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// canonical resolves a name against the valid set, case-insensitively or via synonyms.
+func canonical(name string, valid map[string]bool, synonyms map[string]string) (string, bool) {
+	if valid[name] {
+		return name, true
+	}
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for v := range valid {
+		if strings.ToLower(v) == lower {
+			return v, true
+		}
+	}
+	if mapped, ok := synonyms[lower]; ok && valid[mapped] {
+		return mapped, true
+	}
+	return "", false
+}
+
+// knownLabels returns the queryable labels present in the schema. An empty
+// schema (e.g. not loaded yet) accepts every queryable label.
+func knownLabels(schema db.GraphSchema) map[string]bool {
+	inSchema := map[string]bool{}
+	for _, l := range schema.NodeLabels {
+		inSchema[l] = true
+	}
+	labels := map[string]bool{}
+	for _, l := range queryableLabels {
+		if len(inSchema) == 0 || inSchema[l] {
+			labels[l] = true
+		}
+	}
+	return labels
+}
+
+// knownRelations returns the relation types in the schema plus the preferred ones.
+func knownRelations(schema db.GraphSchema) map[string]bool {
+	relations := map[string]bool{}
+	for _, r := range defaultRelations {
+		relations[r] = true
+	}
+	for _, r := range schema.Relationships {
+		for _, m := range relationPattern.FindAllStringSubmatch(r, -1) {
+			relations[m[1]] = true
+		}
+	}
+	return relations
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// STRUCTURED OUTPUT
+// ─────────────────────────────────────────────────────────────────────────────
+
+// PlanJSONSchema returns the JSON schema GraphQueryPlan output must follow.
+// Node types and filter fields are enums, so the model can't invent labels.
+// Every property is required to satisfy OpenAI's strict mode.
+func PlanJSONSchema(schema db.GraphSchema) llm.JSONSchema {
+	known := knownLabels(schema)
+	var labels []string
+	for _, l := range queryableLabels {
+		if known[l] {
+			labels = append(labels, l)
+		}
+	}
+	var relations []string
+	for r := range knownRelations(schema) {
+		relations = append(relations, r)
+	}
+	sort.Strings(relations)
+	relations = append(relations, "")
+
+	return llm.JSONSchema{
+		Name: "graph_query_plan",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"target_nodes": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string", "enum": labels},
+				},
+				"filters": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"on":       map[string]any{"type": "string", "enum": supportedFilters},
+							"value":    map[string]any{"type": "string"},
+							"relation": map[string]any{"type": "string", "enum": relations},
+							"op":       map[string]any{"type": "string", "enum": []string{db.FilterAnd, db.FilterOr, db.FilterNot}},
+						},
+						"required":             []string{"on", "value", "relation", "op"},
+						"additionalProperties": false,
+					},
+				},
+				"reasoning": map[string]any{"type": "string"},
+			},
+			"required":             []string{"target_nodes", "filters", "reasoning"},
+			"additionalProperties": false,
+		},
+	}
+}
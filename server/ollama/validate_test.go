@@ -0,0 +1,241 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/tenant"
+)
+
+// testSchema is a graph with every queryable label except Education.
+var testSchema = db.GraphSchema{
+	NodeLabels:    []string{"Hobby", "Person", "Project", "Skill", "Tag", "WorkExperience"},
+	Relationships: []string{"(Project)-[:HAS_TAG]->(Tag)", "(Project)-[:USES]->(Skill)"},
+}
+
+func TestSynonymTablesMapToValidNames(t *testing.T) {
+	for synonym, label := range labelSynonyms {
+		if !slices.Contains(queryableLabels, label) {
+			t.Errorf("label synonym %q maps to %q, which isn't queryable", synonym, label)
+		}
+	}
+	for synonym, on := range filterSynonyms {
+		if !slices.Contains(supportedFilters, on) {
+			t.Errorf("filter synonym %q maps to %q, which isn't supported", synonym, on)
+		}
+	}
+	for on := range defaultRelations {
+		if !slices.Contains(supportedFilters, on) {
+			t.Errorf("default relation for unsupported filter %q", on)
+		}
+	}
+}
+
+func TestValidatePlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     GraphQueryPlan
+		want     GraphQueryPlan
+		remapped []string
+		problems []string
+	}{
+		{
+			name: "valid",
+			plan: GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}}},
+			want: GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}}},
+		},
+		{
+			name:     "label synonyms and case",
+			plan:     GraphQueryPlan{TargetNodes: []string{"jobs", "project", "Projects", "me"}},
+			want:     GraphQueryPlan{TargetNodes: []string{"WorkExperience", "Project", "Person"}},
+			remapped: []string{`node "jobs" → "WorkExperience"`, `node "project" → "Project"`, `node "Projects" → "Project"`, `node "me" → "Person"`},
+		},
+		{
+			name:     "filter synonyms",
+			plan:     GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "tech", Value: "Go"}, {On: "Employer", Value: "Acme"}, {On: "major", Value: "Physics"}}},
+			want:     GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "Skill", Value: "Go"}, {On: "Company", Value: "Acme"}, {On: "Field", Value: "Physics"}}},
+			remapped: []string{`filter "tech" → "Skill"`, `filter "Employer" → "Company"`, `filter "major" → "Field"`},
+		},
+		{
+			name:     "invented relation",
+			plan:     GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "Skill", Value: "Go", Relation: "BUILT_WITH"}, {On: "Skill", Value: "React", Relation: "USES"}}},
+			want:     GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: []db.FilterClause{{On: "Skill", Value: "Go", Relation: "HAS_SKILL"}, {On: "Skill", Value: "React", Relation: "USES"}}},
+			remapped: []string{`relation "BUILT_WITH" → "HAS_SKILL"`},
+		},
+		{
+			name:     "unknown node and filter",
+			plan:     GraphQueryPlan{TargetNodes: []string{"Planet", "Project"}, Filters: []db.FilterClause{{On: "Color", Value: "red"}}},
+			want:     GraphQueryPlan{TargetNodes: []string{"Planet", "Project"}, Filters: []db.FilterClause{{On: "Color", Value: "red"}}},
+			problems: []string{`unknown node type "Planet"`, `unsupported filter field "Color"`},
+		},
+		{
+			name:     "label missing from the schema",
+			plan:     GraphQueryPlan{TargetNodes: []string{"degree"}},
+			want:     GraphQueryPlan{TargetNodes: []string{"degree"}},
+			problems: []string{`unknown node type "degree"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remapped, problems := ValidatePlan(&tt.plan, testSchema)
+			if fmt.Sprint(tt.plan) != fmt.Sprint(tt.want) {
+				t.Errorf("plan = %+v, want %+v", tt.plan, tt.want)
+			}
+			if !slices.Equal(remapped, tt.remapped) {
+				t.Errorf("remapped = %q, want %q", remapped, tt.remapped)
+			}
+			if len(problems) != len(tt.problems) {
+				t.Fatalf("problems = %q, want %d starting %q", problems, len(tt.problems), tt.problems)
+			}
+			for i, p := range problems {
+				if !strings.HasPrefix(p, tt.problems[i]) {
+					t.Errorf("problem %d = %q, want it to start %q", i, p, tt.problems[i])
+				}
+			}
+		})
+	}
+}
+
+// plannerFixture is the graph the planner tests plan against.
+var plannerFixture = db.Export{
+	Graph: db.ResumeGraph{
+		Person:   &db.Person{ID: "me", Name: "Ada Example"},
+		Projects: []db.Project{{ID: "atlas", Name: "Atlas"}, {ID: "beacon", Name: "Beacon"}},
+		Skills:   []db.Skill{{Name: "Go"}, {Name: "React"}},
+		Tags:     []db.Tag{{Name: "Backend"}},
+		Relationships: []db.Relationship{
+			{Type: "HAS_TAG", From: db.NodeRef{Label: "Project", Key: "atlas"}, To: db.NodeRef{Label: "Tag", Key: "Backend"}},
+			{Type: "USES", From: db.NodeRef{Label: "Project", Key: "atlas"}, To: db.NodeRef{Label: "Skill", Key: "Go"}},
+		},
+	},
+}
+
+// usePlanner installs a fake planner model for the test.
+func usePlanner(t *testing.T, rules ...llm.FakeRule) *llm.Fake {
+	t.Helper()
+	planner := &llm.Fake{ModelName: "fake-planner", Rules: rules}
+	llm.Use(llm.RolePlanner, planner)
+	t.Cleanup(func() { llm.Use(llm.RolePlanner, nil) })
+	return planner
+}
+
+const (
+	validPlan   = `{"target_nodes": ["Project"], "filters": [{"on": "Tag", "value": "Backend", "relation": "HAS_TAG"}], "reasoning": "backend work"}`
+	invalidPlan = `{"target_nodes": ["Project", "Planet"], "filters": [{"on": "Tag", "value": "Backend"}, {"on": "Color", "value": "red"}]}`
+	repairMatch = "Your previous plan was invalid"
+)
+
+func TestPlanGraphQueryOutcomes(t *testing.T) {
+	t.Setenv("PLANNER_FASTPATH_ENABLED", "false")
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+
+	tests := []struct {
+		name     string
+		rules    []llm.FakeRule
+		outcome  string
+		attempts int
+		targets  []string
+		filters  []db.FilterClause
+		remapped int
+		errors   int
+	}{
+		{
+			name:     "valid",
+			rules:    []llm.FakeRule{{Match: "QUESTION:", Reply: validPlan}},
+			outcome:  db.PlanValid,
+			attempts: 1,
+			targets:  []string{"Project"},
+			filters:  []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}},
+		},
+		{
+			name:     "remapped synonyms",
+			rules:    []llm.FakeRule{{Match: "QUESTION:", Reply: `{"target_nodes": ["projects"], "filters": [{"on": "tech", "value": "go"}]}`}},
+			outcome:  db.PlanRemapped,
+			attempts: 1,
+			targets:  []string{"Project"},
+			filters:  []db.FilterClause{{On: "Skill", Value: "Go"}},
+			remapped: 2,
+		},
+		{
+			name:     "remapped owner name",
+			rules:    []llm.FakeRule{{Match: "QUESTION:", Reply: `{"target_nodes": ["Ada"], "filters": []}`}},
+			outcome:  db.PlanRemapped,
+			attempts: 1,
+			targets:  []string{"Person"},
+			filters:  []db.FilterClause{},
+			remapped: 1,
+		},
+		{
+			name:     "repaired",
+			rules:    []llm.FakeRule{{Match: repairMatch, Reply: validPlan}, {Match: "QUESTION:", Reply: invalidPlan}},
+			outcome:  db.PlanRepaired,
+			attempts: 2,
+			targets:  []string{"Project"},
+			filters:  []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}},
+			errors:   2,
+		},
+		{
+			name:     "repaired after prose",
+			rules:    []llm.FakeRule{{Match: repairMatch, Reply: validPlan}, {Match: "QUESTION:", Reply: "Projects tagged Backend, I think."}},
+			outcome:  db.PlanRepaired,
+			attempts: 2,
+			targets:  []string{"Project"},
+			filters:  []db.FilterClause{{On: "Tag", Value: "Backend", Relation: "HAS_TAG"}},
+			errors:   1,
+		},
+		{
+			name:     "degraded",
+			rules:    []llm.FakeRule{{Match: "QUESTION:", Reply: invalidPlan}},
+			outcome:  db.PlanDegraded,
+			attempts: maxRepairAttempts + 1,
+			targets:  []string{"Project"},
+			filters:  []db.FilterClause{{On: "Tag", Value: "Backend"}},
+			errors:   2 * (maxRepairAttempts + 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := usePlanner(t, tt.rules...)
+			p := NewPlanner(db.NewMemoryRepository(plannerFixture))
+
+			plan, err := p.PlanGraphQuery(ctx, "Which backend projects did you build?", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			trace := plan.Trace
+			if trace.Planner != db.PlannerLLM || trace.Outcome != tt.outcome || trace.Attempts != tt.attempts {
+				t.Errorf("trace = %s %s after %d attempt(s), want llm %s after %d", trace.Planner, trace.Outcome, trace.Attempts, tt.outcome, tt.attempts)
+			}
+			if len(planner.Calls) != tt.attempts {
+				t.Errorf("planner called %d times, want %d", len(planner.Calls), tt.attempts)
+			}
+			if len(trace.Remapped) != tt.remapped || len(trace.Errors) != tt.errors {
+				t.Errorf("trace remapped %q and errors %q, want %d and %d", trace.Remapped, trace.Errors, tt.remapped, tt.errors)
+			}
+			if !slices.Equal(plan.TargetNodes, tt.targets) || fmt.Sprint(plan.Filters) != fmt.Sprint(tt.filters) {
+				t.Errorf("plan targets %v, filters %+v; want %v, %+v", plan.TargetNodes, plan.Filters, tt.targets, tt.filters)
+			}
+		})
+	}
+}
+
+func TestPlanGraphQueryWithoutUsablePlan(t *testing.T) {
+	t.Setenv("PLANNER_FASTPATH_ENABLED", "false")
+	planner := usePlanner(t, llm.FakeRule{Match: "QUESTION:", Reply: "I can't help with that."})
+	p := NewPlanner(db.NewMemoryRepository(plannerFixture))
+
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+	if _, err := p.PlanGraphQuery(ctx, "Which backend projects did you build?", ""); err == nil {
+		t.Error("a planner that never returns JSON gave a plan")
+	}
+	if len(planner.Calls) != maxRepairAttempts+1 {
+		t.Errorf("planner called %d times, want %d", len(planner.Calls), maxRepairAttempts+1)
+	}
+}
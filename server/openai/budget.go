@@ -0,0 +1,162 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"go-ai/config"
+	"go-ai/db"
+	"go-ai/llm"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority weights added to a candidate's fused rank score. A single retriever
+// hit scores about 1/61, so these nudge ties rather than override relevance.
+const (
+	featuredWeight = 0.01
+	recencyWeight  = 0.01
+)
+
+// maxAutoContextTokens caps the automatic budget on very large context windows.
+const maxAutoContextTokens = 3000
+
+// minBlockTokens is the smallest truncated block worth including.
+const minBlockTokens = 24
+
+// ContextBudget reports how the context for one answer was assembled.
+type ContextBudget struct {
+	Budget    int
+	Used      int
+	Truncated []string
+	Dropped   []string
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Budgeting
+// ─────────────────────────────────────────────────────────────────────────────
+
+// contextTokenBudget returns CONTEXT_TOKEN_BUDGET, or a quarter of the answer
+// model's context window (capped at maxAutoContextTokens) when unset.
+func contextTokenBudget(ctx context.Context) int {
+	if budget := config.GetContextTokenBudget(); budget > 0 {
+		return budget
+	}
+	model := llm.ForRole(ctx, llm.RoleAnswerer).Model()
+	return min(llm.ContextWindow(model)/4, maxAutoContextTokens)
+}
+
+// fitToBudget keeps the highest-priority candidates whose rendered lines fit
+// in budget tokens, as counted by the answer model's tokenizer. Long fields are
+// cut to CONTEXT_FIELD_TOKEN_LIMIT first, and a block that only partly fits is
+// truncated rather than dropped when enough room is left. Kept candidates stay
+// in priority order.
+func fitToBudget(candidates []Candidate, budget int, tokens llm.Tokenizer) ([]Candidate, ContextBudget) {
+	report := ContextBudget{Budget: budget}
+	fieldLimit := config.GetContextFieldTokenLimit()
+
+	prioritised := make([]Candidate, len(candidates))
+	copy(prioritised, candidates)
+	now := time.Now()
+	sort.SliceStable(prioritised, func(i, j int) bool {
+		return priority(prioritised[i], now) > priority(prioritised[j], now)
+	})
+
+	var kept []Candidate
+	for _, c := range prioritised {
+		line, cut := truncateFields(c.Line, fieldLimit, tokens)
+		tagCost := tokens.CountTokens(citationTag(c.Source()) + " ")
+		cost := tokens.CountTokens(line) + tagCost
+		remaining := budget - report.Used
+
+		if cost > remaining {
+			if remaining < minBlockTokens {
+				report.Dropped = append(report.Dropped, describe(c))
+				continue
+			}
+			line = truncateTokens(line, remaining-tagCost, tokens) + "\n"
+			cost = tokens.CountTokens(line) + tagCost
+			cut = true
+		}
+
+		if cut {
+			report.Truncated = append(report.Truncated, describe(c))
+		}
+		c.Line = line
+		kept = append(kept, c)
+		report.Used += cost
+	}
+
+	log.Printf("context budget: %d/%d tokens, kept %d, truncated %v, dropped %v",
+		report.Used, report.Budget, len(kept), report.Truncated, report.Dropped)
+	return kept, report
+}
+
+// priority ranks a candidate by relevance, then featured status and recency.
+func priority(c Candidate, now time.Time) float64 {
+	p := c.Score
+	if c.Featured {
+		p += featuredWeight
+	}
+	return p + recencyWeight*recency(c.Date, now)
+}
+
+// recency maps a date to (0, 1], halving for every two years in the past.
+// Ongoing ("Present") items score 1; undated or unparseable items score 0.
+func recency(date string, now time.Time) float64 {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return 0
+	}
+	if strings.EqualFold(date, "present") || strings.EqualFold(date, "current") {
+		return 1
+	}
+	t, ok := db.ParseDate(date)
+	if !ok {
+		return 0
+	}
+	years := max(now.Sub(t).Hours()/(24*365), 0)
+	return math.Pow(0.5, years/2)
+}
+
+// truncateFields shortens each line of a rendered block (one field or
+// contribution per line) to at most limit tokens and reports whether any
+// line was cut.
+func truncateFields(block string, limit int, tokens llm.Tokenizer) (string, bool) {
+	if limit <= 0 {
+		return block, false
+	}
+	lines := strings.Split(block, "\n")
+	cut := false
+	for i, line := range lines {
+		if tokens.CountTokens(line) > limit {
+			lines[i] = truncateTokens(line, limit, tokens)
+			cut = true
+		}
+	}
+	return strings.Join(lines, "\n"), cut
+}
+
+// truncateTokens cuts text to about limit tokens at a word boundary and marks
+// the cut with an ellipsis.
+func truncateTokens(text string, limit int, tokens llm.Tokenizer) string {
+	text = strings.TrimRight(text, "\n")
+	if tokens.CountTokens(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	n := sort.Search(len(runes), func(i int) bool {
+		return tokens.CountTokens(string(runes[:i+1])+"…") > limit
+	})
+	cut := string(runes[:n])
+	if i := strings.LastIndexAny(cut, " \n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+func describe(c Candidate) string {
+	return fmt.Sprintf("%s %q", c.Label, c.Name)
+}
@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+
+	"go-ai/llm"
+)
+
+func TestTruncateTokensCountsWithTheModelsTokenizer(t *testing.T) {
+	text := strings.Repeat("Built a map tile server that renders vector tiles on demand. ", 20)
+	const limit = 40
+
+	kept := map[string]int{}
+	for _, model := range []string{"gpt-4o", "gpt-3.5-turbo", "llama2", "unknown"} {
+		tokens := llm.TokenizerForModel(model)
+		cut := truncateTokens(text, limit, tokens)
+		if n := tokens.CountTokens(cut); n > limit || n < limit-5 {
+			t.Errorf("%s: truncated to %d tokens, want close to %d", model, n, limit)
+		}
+		if !strings.HasSuffix(cut, "…") {
+			t.Errorf("%s: truncation isn't marked: %q", model, cut)
+		}
+		kept[model] = len(cut)
+	}
+	if kept["gpt-4o"] <= kept["llama2"] {
+		t.Errorf("gpt-4o kept %d characters, llama2 %d; want more for the larger vocabulary", kept["gpt-4o"], kept["llama2"])
+	}
+
+	if got := truncateTokens("Short enough", limit, llm.TokenizerForModel("gpt-4o")); got != "Short enough" {
+		t.Errorf("text within the limit was cut to %q", got)
+	}
+}
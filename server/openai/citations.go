@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"fmt"
+	"go-ai/db"
+	"go-ai/llm"
+	"regexp"
+	"strings"
+)
+
+// citationInstructions tells the model how to cite the tagged context blocks.
+const citationInstructions = `Each item above starts with a source tag such as [Project:abc123].
+After a sentence that uses an item, add that item's tag exactly as written. Never invent tags.`
+
+// Source converts a ranked candidate into the source it would be cited as.
+func (c Candidate) Source() db.Source {
+	return db.Source{ID: c.Key, Type: c.Label, Name: c.Name, Link: c.Link}
+}
+
+// citationTag is the marker a context block is tagged with and cited by.
+func citationTag(s db.Source) string {
+	return fmt.Sprintf("[%s:%s]", s.Type, s.ID)
+}
+
+// citationPattern matches a citationTag (a label, a colon and an ID on one
+// line, in brackets) with the spaces before it, cited or invented alike. IDs
+// may contain spaces, since skills and hobbies are keyed by name, but are
+// capped so a stray "[Note: ..." isn't held back for the rest of a stream.
+var citationPattern = regexp.MustCompile(`[ \t]*\[[A-Za-z]+:[^\[\]\n]{1,100}\]`)
+
+// partialCitation matches the start of a citationTag still being streamed.
+var partialCitation = regexp.MustCompile(`^\[[A-Za-z]*(:[^\[\]\n]{0,100})?$`)
+
+// citedSources returns the offered sources whose tags appear in the reply, in
+// the order they were offered; none if the model cited nothing.
+func citedSources(reply string, offered []db.Source) []db.Source {
+	cited := []db.Source{}
+	for _, s := range offered {
+		if strings.Contains(reply, citationTag(s)) {
+			cited = append(cited, s)
+		}
+	}
+	return cited
+}
+
+// stripCitations removes the citation tags from a reply before visitors see it.
+func stripCitations(reply string) string {
+	return citationPattern.ReplaceAllString(reply, "")
+}
+
+// citationFilter strips citation tags from a streamed reply. A tag can arrive
+// split over several tokens, so text from an unclosed "[" on, and the spaces
+// before it, is held back until it is known whether it forms one.
+type citationFilter struct {
+	onToken llm.TokenFunc
+	pending string
+}
+
+// Write receives the next streamed token.
+func (f *citationFilter) Write(token string) error {
+	f.pending += token
+	return f.release(heldFrom(f.pending))
+}
+
+// Flush sends whatever is still held back once the stream has ended.
+func (f *citationFilter) Flush() error {
+	return f.release(len(f.pending))
+}
+
+// release sends the pending text up to end, without citation tags.
+func (f *citationFilter) release(end int) error {
+	text := stripCitations(f.pending[:end])
+	f.pending = f.pending[end:]
+	if text == "" {
+		return nil
+	}
+	return f.onToken(text)
+}
+
+// heldFrom returns where the text that may still turn into a citation tag
+// starts: an unclosed tag at the end, or trailing spaces a tag may follow.
+func heldFrom(text string) int {
+	end := len(text)
+	if i := strings.LastIndex(text, "["); i >= 0 && partialCitation.MatchString(text[i:]) {
+		end = i
+	}
+	return len(strings.TrimRight(text[:end], " \t"))
+}
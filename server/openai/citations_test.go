@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+
+	"go-ai/db"
+)
+
+func TestCitedSources(t *testing.T) {
+	offered := []db.Source{{ID: "p1", Type: "Project"}, {ID: "w1", Type: "WorkExperience"}, {ID: "Machine Learning", Type: "Skill"}}
+
+	cited := citedSources("I built it [Project:p1].", offered)
+	if len(cited) != 1 || cited[0].ID != "p1" {
+		t.Errorf("cited = %+v, want only p1", cited)
+	}
+	if cited := citedSources("I trained models [Skill:Machine Learning].", offered); len(cited) != 1 || cited[0].ID != "Machine Learning" {
+		t.Errorf("cited = %+v, want only Machine Learning", cited)
+	}
+	if cited := citedSources("No tags here.", offered); cited == nil || len(cited) != 0 {
+		t.Errorf("uncited reply gave %#v, want an empty slice", cited)
+	}
+}
+
+func TestStripCitations(t *testing.T) {
+	tests := []struct {
+		reply, want string
+	}{
+		{"I built Atlas [Project:p1]. Then I joined Acme [WorkExperience:4:ab:7][Project:p2].", "I built Atlas. Then I joined Acme."},
+		{"I know ML [Skill:Machine Learning] and play games [Hobby:Competitive Gaming].", "I know ML and play games."},
+		{"Arrays like a[0] stay [sic] intact", "Arrays like a[0] stay [sic] intact"},
+		{"A note [Note:\nacross lines] stays", "A note [Note:\nacross lines] stays"},
+	}
+	for _, tt := range tests {
+		if got := stripCitations(tt.reply); got != tt.want {
+			t.Errorf("stripCitations(%q) = %q, want %q", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestCitationFilterStreamsWithoutTags(t *testing.T) {
+	reply := "I built Atlas [Project:p1]. Arrays like a[0] stay [sic] intact [WorkExperience:w1] and I play [Hobby:Competitive Gaming]."
+	for _, size := range []int{1, 2, 3, 5, 8, len(reply)} {
+		var out strings.Builder
+		f := &citationFilter{onToken: func(token string) error {
+			out.WriteString(token)
+			return nil
+		}}
+		for i := 0; i < len(reply); i += size {
+			if err := f.Write(reply[i:min(i+size, len(reply))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if want := "I built Atlas. Arrays like a[0] stay [sic] intact and I play."; out.String() != want {
+			t.Errorf("chunks of %d streamed %q, want %q", size, out.String(), want)
+		}
+	}
+}
@@ -1,15 +1,43 @@
 package openai
 
 import (
+	"context"
 	"fmt"
+	"go-ai/config"
 	"go-ai/db"
+	"go-ai/llm"
 	"go-ai/ollama"
 	"strings"
 )
 
-// BuildContextFromGraphPlan gathers relevant context from Neo4j based on a structured query plan.
-func BuildContextFromGraphPlan(plan ollama.GraphQueryPlan) (string, error) {
+// sectionHeaders are the context headings for each node type, in display order.
+var sectionHeaders = []struct {
+	Label  string
+	Header string
+}{
+	{"Project", "Relevant Projects:"},
+	{"WorkExperience", "Work Experience:"},
+	{"Education", "Education:"},
+	{"Hobby", "Hobbies:"},
+	{"Skill", "Skills:"},
+}
+
+// GraphContext is the answer context built for one question.
+type GraphContext struct {
+	Text    string
+	Sources []db.Source // every source tagged in Text
+	Budget  ContextBudget
+}
+
+// BuildContextFromGraphPlan gathers relevant context from the resume graph based on a structured query plan.
+// Candidates from the planner, keyword and vector retrievers are ranked together; the best
+// RETRIEVAL_MAX_RESULTS are then fitted into the answer model's token budget, counted the way
+// its tokenizer would. Every block is tagged with its source (see citationTag).
+func (a *Assistant) BuildContextFromGraphPlan(ctx context.Context, plan ollama.GraphQueryPlan) (GraphContext, error) {
 	var contextParts []string
+	var sources []db.Source
+	budget := contextTokenBudget(ctx)
+	tokens := llm.TokenizerFor(llm.ForRole(ctx, llm.RoleAnswerer))
 
 	// Filter out empty or placeholder values
 	var validFilters []db.FilterClause
@@ -21,109 +49,80 @@ func BuildContextFromGraphPlan(plan ollama.GraphQueryPlan) (string, error) {
 	plan.Filters = validFilters
 
 	for _, nodeType := range plan.TargetNodes {
-		switch nodeType {
-		case "Project":
-			projects, err := db.FindProjectsWithFilters(plan.Filters)
-			if err != nil || len(projects) == 0 {
-				// Fallback: get all projects if none found
-				projects, err = db.FindProjectsWithFilters(nil)
-				if err != nil || len(projects) == 0 {
-					continue
-				}
-			}
-			var b strings.Builder
-			b.WriteString("Relevant Projects:\n")
-			for _, p := range projects {
-				b.WriteString(fmt.Sprintf("- %s: %s\n", p.Name, p.Description))
-				if len(p.Contributions) > 0 {
-					b.WriteString("  Contributions:\n")
-					for _, c := range p.Contributions {
-						b.WriteString(fmt.Sprintf("    • %s\n", c))
-					}
-				}
-			}
-			contextParts = append(contextParts, b.String())
+		if nodeType != "Person" {
+			continue
+		}
+		person, err := a.Resume.GetPerson(ctx)
+		if err != nil {
+			continue
+		}
+		source := db.Source{ID: person.ID, Type: "Person", Name: person.Name}
+		bio := "About:\n" + strings.Replace(renderPerson(*person), "- ", "- "+citationTag(source)+" ", 1)
+		contextParts = append(contextParts, bio)
+		sources = append(sources, source)
+		budget -= tokens.CountTokens(bio)
+	}
 
-		case "WorkExperience":
-			experiences, err := db.FindWorkExperienceWithFilters(plan.Filters)
-			if err != nil || len(experiences) == 0 {
-				// Fallback: get all work experiences if none found
-				experiences, err = db.FindWorkExperienceWithFilters(nil)
-				if err != nil || len(experiences) == 0 {
-					continue
-				}
-			}
-			var b strings.Builder
-			b.WriteString("Work Experience:\n")
-			for _, w := range experiences {
-				b.WriteString(fmt.Sprintf("- %s at %s: %s\n", w.Title, w.Company, w.Summary))
-			}
-			contextParts = append(contextParts, b.String())
+	ranked := a.RankCandidates(ctx, plan, plan.RawInput, config.GetRetrievalMaxResults())
+	kept, report := fitToBudget(ranked, budget, tokens)
+	contextParts = append(contextParts, renderSections(kept)...)
+	for _, c := range kept {
+		sources = append(sources, c.Source())
+	}
 
-		case "Education":
-			education, err := db.FindEducationWithFilters(plan.Filters)
-			if err != nil || len(education) == 0 {
-				// Fallback: get all education if none found
-				education, err = db.FindEducationWithFilters(nil)
-				if err != nil || len(education) == 0 {
-					continue
-				}
-			}
-			var b strings.Builder
-			b.WriteString("Education:\n")
-			for _, e := range education {
-				b.WriteString(fmt.Sprintf("- %s at %s: %s\n", e.Degree, e.Institution, e.Summary))
-			}
-			contextParts = append(contextParts, b.String())
+	return GraphContext{
+		Text:    strings.Join(contextParts, "\n\n"),
+		Sources: sources,
+		Budget:  report,
+	}, nil
+}
 
-		case "Hobby":
-			hobbies, err := db.FindHobbiesWithFilters(plan.Filters)
-			if err != nil || len(hobbies) == 0 {
-				// Fallback: get all hobbies if none found
-				hobbies, err = db.FindHobbiesWithFilters(nil)
-				if err != nil || len(hobbies) == 0 {
-					continue
-				}
+// renderSections groups ranked candidates under their type headings, keeping
+// rank order within each section.
+func renderSections(ranked []Candidate) []string {
+	var sections []string
+	for _, s := range sectionHeaders {
+		var b strings.Builder
+		for _, c := range ranked {
+			if c.Label == s.Label {
+				b.WriteString(strings.Replace(c.Line, "- ", "- "+citationTag(c.Source())+" ", 1))
 			}
-			var b strings.Builder
-			b.WriteString("Hobbies:\n")
-			for _, h := range hobbies {
-				b.WriteString(fmt.Sprintf("- %s: %s\n", h.Name, h.Description))
-			}
-			contextParts = append(contextParts, b.String())
+		}
+		if b.Len() > 0 {
+			sections = append(sections, s.Header+"\n"+b.String())
+		}
+	}
+	return sections
+}
 
-		case "Skill":
-			skills, err := db.FindSkillsWithFilters(plan.Filters)
-			if err != nil || len(skills) == 0 {
-				// Fallback: get all skills if none found
-				skills, err = db.FindSkillsWithFilters(nil)
-				if err != nil || len(skills) == 0 {
-					continue
-				}
-			}
-			var b strings.Builder
-			b.WriteString("Skills:\n")
-			for _, s := range skills {
-				b.WriteString(fmt.Sprintf("- %s\n", s.Name))
-			}
-			contextParts = append(contextParts, b.String())
+// ─────────────────────────────────────────────────────────────────────────────
+// Node rendering
+// ─────────────────────────────────────────────────────────────────────────────
 
-		case "Person":
-			person, err := db.GetPerson()
-			if err != nil {
-				continue
-			}
-			bio := fmt.Sprintf(
-				"%s is a %s based in %s. With a background in %s and pronouns %s, she brings a love for problem solving and gaming into all her work. She’s especially passionate about cybersecurity, EV infrastructure, and full-stack development.",
-				person.Name,
-				strings.ToLower(person.Summary),
-				person.Location,
-				strings.Join(person.Background, " and "),
-				person.Pronouns,
-			)
-			contextParts = append(contextParts, bio)
+func renderProject(p db.Project) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("- %s: %s\n", p.Name, p.Description))
+	if len(p.Contributions) > 0 {
+		b.WriteString("  Contributions:\n")
+		for _, c := range p.Contributions {
+			b.WriteString(fmt.Sprintf("    • %s\n", c))
 		}
 	}
+	return b.String()
+}
+
+func renderWorkExperience(w db.WorkExperience) string {
+	return fmt.Sprintf("- %s at %s: %s\n", w.Title, w.Company, w.Summary)
+}
+
+func renderEducation(e db.Education) string {
+	return fmt.Sprintf("- %s at %s: %s\n", e.Degree, e.Institution, e.Summary)
+}
+
+func renderHobby(h db.Hobby) string {
+	return fmt.Sprintf("- %s: %s\n", h.Name, h.Description)
+}
 
-	return strings.Join(contextParts, "\n\n"), nil
+func renderSkill(s db.Skill) string {
+	return fmt.Sprintf("- %s\n", s.Name)
 }
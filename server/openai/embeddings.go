@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"go-ai/db"
+	"go-ai/llm"
+)
+
+// embedBatchSize caps how many node texts are sent per embedding request.
+const embedBatchSize = 32
+
+// IndexReport summarises one run of the embedding indexer.
+type IndexReport struct {
+	Total    int
+	Embedded int
+	Skipped  int
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Indexing
+// ─────────────────────────────────────────────────────────────────────────────
+
+// IndexEmbeddings embeds every Project, WorkExperience, Education, Hobby and
+// Skill node whose text changed since it was last embedded, stores the vectors
+// on the nodes and makes sure the vector indexes exist.
+func IndexEmbeddings(ctx context.Context) (IndexReport, error) {
+	nodes, err := db.ListEmbeddableNodes(ctx)
+	if err != nil {
+		return IndexReport{}, err
+	}
+
+	report := IndexReport{Total: len(nodes)}
+	var stale []db.EmbeddableNode
+	for _, n := range nodes {
+		if n.Text == "" || n.StoredHash == n.Hash() {
+			report.Skipped++
+			continue
+		}
+		stale = append(stale, n)
+	}
+
+	provider := llm.ForRole(ctx, llm.RoleEmbedder)
+	dimensions := 0
+	for start := 0; start < len(stale); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(stale))
+		batch := stale[start:end]
+
+		texts := make([]string, len(batch))
+		for i, n := range batch {
+			texts[i] = n.Text
+		}
+		vectors, err := provider.Embed(ctx, texts)
+		if err != nil {
+			return report, fmt.Errorf("failed to embed nodes: %w", err)
+		}
+
+		for i, n := range batch {
+			if len(vectors[i]) == 0 {
+				continue
+			}
+			if err := db.SetNodeEmbedding(ctx, n.ElementID, vectors[i], n.Hash()); err != nil {
+				return report, fmt.Errorf("failed to store embedding for %s %q: %w", n.Label, n.Name, err)
+			}
+			dimensions = len(vectors[i])
+			report.Embedded++
+		}
+	}
+
+	if dimensions > 0 {
+		if err := db.EnsureVectorIndexes(ctx, dimensions); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Retrieval
+// ─────────────────────────────────────────────────────────────────────────────
+
+// SemanticSearch returns the k resume nodes whose embeddings are closest to
+// the question.
+func (a *Assistant) SemanticSearch(ctx context.Context, question string, k int) ([]db.SimilarNode, error) {
+	vectors, err := llm.ForRole(ctx, llm.RoleEmbedder).Embed(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed question: %w", err)
+	}
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return nil, fmt.Errorf("embedding provider returned no vector")
+	}
+	return a.Resume.SearchSimilarNodes(ctx, vectors[0], k)
+}
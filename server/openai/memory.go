@@ -0,0 +1,187 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"go-ai/config"
+	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/tenant"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Types
+// ─────────────────────────────────────────────────────────────────────────────
+
+// ConversationMemory is the part of a user's chat history folded into prompts.
+type ConversationMemory struct {
+	Summary string           // rolling summary of turns older than Turns
+	Turns   []db.ChatMessage // most recent messages, oldest first
+}
+
+// summarising tracks conversations whose summary is currently being refreshed.
+var summarising sync.Map
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Loading
+// ─────────────────────────────────────────────────────────────────────────────
+
+// LoadConversationMemory returns the most recent turns of a user's
+// conversation that fit the configured turn and token budget; an empty
+// conversationID means the messages sent outside any conversation. When
+// summarisation is enabled it also returns the stored summary of older turns
+// and refreshes it in the background.
+func (a *Assistant) LoadConversationMemory(ctx context.Context, userID, conversationID string) (ConversationMemory, error) {
+	if userID == "" {
+		return ConversationMemory{}, nil
+	}
+
+	// Without summaries nothing older than the turn window is ever used
+	var q db.ConversationQuery
+	if !config.GetMemorySummaryEnabled() {
+		q.Limit = config.GetMemoryMaxTurns() * 2
+	}
+	history, err := a.Chats.GetConversationMessages(ctx, userID, conversationID, q)
+	if err != nil {
+		return ConversationMemory{}, fmt.Errorf("failed to load chat history: %w", err)
+	}
+
+	recent, older := selectRecentTurns(history, config.GetMemoryMaxTurns()*2, config.GetMemoryTokenBudget())
+	memory := ConversationMemory{Turns: recent}
+
+	if !config.GetMemorySummaryEnabled() || len(older) == 0 {
+		return memory, nil
+	}
+
+	summary, err := a.Chats.GetSummary(ctx, userID, conversationID)
+	if err != nil {
+		log.Printf("[WARN] Failed to load conversation summary: %v", err)
+		return memory, nil
+	}
+	if summary != nil {
+		memory.Summary = summary.Summary
+	}
+	a.refreshSummaryAsync(ctx, userID, conversationID, summary, older)
+
+	return memory, nil
+}
+
+// selectRecentTurns walks back from the newest message until either limit is
+// hit and splits history into the recent window and everything older.
+func selectRecentTurns(history []db.ChatMessage, maxMessages, tokenBudget int) (recent, older []db.ChatMessage) {
+	start := len(history)
+	used := 0
+	for start > 0 && len(history)-start < maxMessages {
+		m := history[start-1]
+		cost := llm.EstimateMessageTokens(llm.Message{Role: m.Role, Content: m.Content})
+		if used+cost > tokenBudget {
+			break
+		}
+		used += cost
+		start--
+	}
+
+	// Don't open the window on a reply whose question was cut off
+	if start < len(history) && history[start].Role == "assistant" {
+		start++
+	}
+	return history[start:], history[:start]
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Prompt rendering
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Transcript renders the memory as plain text for single-prompt models.
+func (m ConversationMemory) Transcript() string {
+	var b strings.Builder
+	if m.Summary != "" {
+		b.WriteString(fmt.Sprintf("Earlier in the conversation: %s\n", m.Summary))
+	}
+	for _, t := range m.Turns {
+		speaker := "User"
+		if t.Role == "assistant" {
+			speaker = "Assistant"
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\n", speaker, t.Content))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Messages renders the memory as chat messages to insert before the new question.
+func (m ConversationMemory) Messages() []db.ChatMessage {
+	var messages []db.ChatMessage
+	if m.Summary != "" {
+		messages = append(messages, db.ChatMessage{
+			Role:    "system",
+			Content: "Summary of the earlier conversation: " + m.Summary,
+		})
+	}
+	for _, t := range m.Turns {
+		messages = append(messages, db.ChatMessage{Role: t.Role, Content: t.Content})
+	}
+	return messages
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Rolling summary
+// ─────────────────────────────────────────────────────────────────────────────
+
+// refreshSummaryAsync folds older messages not yet covered by the stored
+// summary into it and stores the result back in the chat store.
+func (a *Assistant) refreshSummaryAsync(ctx context.Context, userID, conversationID string, existing *db.ConversationSummary, older []db.ChatMessage) {
+	var pending []db.ChatMessage
+	previous := ""
+	for _, m := range older {
+		if existing == nil || m.Timestamp.After(existing.CoveredUntil) {
+			pending = append(pending, m)
+		}
+	}
+	if existing != nil {
+		previous = existing.Summary
+	}
+	if len(pending) == 0 {
+		return
+	}
+	key := tenant.FromContext(ctx).ID + "/" + userID + "/" + conversationID
+	if _, busy := summarising.LoadOrStore(key, true); busy {
+		return
+	}
+
+	// Outlive the request but keep its tenant
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer summarising.Delete(key)
+
+		prompt := fmt.Sprintf(`Update the running summary of a chat between a visitor and a portfolio assistant.
+Keep it under 120 words. Keep names of projects, companies and technologies that were discussed.
+
+Current summary:
+%s
+
+New messages:
+%s
+
+Updated summary:`, previous, ConversationMemory{Turns: pending}.Transcript())
+
+		summary, err := llm.ForRole(ctx, llm.RoleAnswerer).Chat(ctx, []llm.Message{
+			{Role: "user", Content: prompt},
+		})
+		if err != nil {
+			log.Printf("[WARN] Failed to summarise conversation: %v", err)
+			return
+		}
+
+		if err := a.Chats.StoreSummary(ctx, db.ConversationSummary{
+			UserID:         userID,
+			ConversationID: conversationID,
+			Summary:        strings.TrimSpace(summary),
+			CoveredUntil:   pending[len(pending)-1].Timestamp,
+		}); err != nil {
+			log.Printf("[WARN] Failed to store conversation summary: %v", err)
+		}
+	}()
+}
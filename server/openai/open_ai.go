@@ -1,33 +1,15 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"go-ai/config"
 	"go-ai/db"
+	"go-ai/llm"
 	"go-ai/ollama"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 )
 
-// ─────────────────────────────────────────────────────────────────────────────
-// Structs
-// ─────────────────────────────────────────────────────────────────────────────
-
-type OpenAIChatRequest struct {
-	Model    string           `json:"model"`
-	Messages []db.ChatMessage `json:"messages"`
-}
-
-type OpenAIChatResponse struct {
-	Choices []struct {
-		Message db.ChatMessage `json:"message"`
-	} `json:"choices"`
-}
-
 // ─────────────────────────────────────────────────────────────────────────────
 // Utility: Detects casual/non-query user input
 // ─────────────────────────────────────────────────────────────────────────────
@@ -57,65 +39,116 @@ func isNonQuery(input string) bool {
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
-// CallOpenAI: Chat Completion API wrapper
+// CallOpenAI: Chat wrapper around the configured answer provider
 // ─────────────────────────────────────────────────────────────────────────────
 
-func CallOpenAI(messages []db.ChatMessage, model string) (string, error) {
-	apiKey := config.GetOpenAIKey()
-	reqBody := OpenAIChatRequest{
-		Model:    model,
-		Messages: messages,
-	}
+// CallOpenAI sends the messages to the answerer provider (an OpenAI-compatible
+// backend by default, see ANSWER_PROVIDER / ANSWER_MODEL) and returns the reply.
+func CallOpenAI(ctx context.Context, messages []db.ChatMessage) (string, error) {
+	return llm.ForRole(ctx, llm.RoleAnswerer).Chat(ctx, toLLMMessages(messages))
+}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", err
+// toLLMMessages converts stored chat messages to the provider-neutral format.
+func toLLMMessages(messages []db.ChatMessage) []llm.Message {
+	out := make([]llm.Message, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, llm.Message{Role: m.Role, Content: m.Content})
 	}
+	return out
+}
 
-	req, err := http.NewRequest("POST", config.GetOpenAIChatURL(), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+// ─────────────────────────────────────────────────────────────────────────────
+// SmartQuery: Main entry for user Q&A using the resume graph and OpenAI
+// ─────────────────────────────────────────────────────────────────────────────
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", err
+// Assistant answers questions about the resume graph it reads from and keeps
+// each user's conversation in its chat store.
+type Assistant struct {
+	Resume  db.ResumeRepository
+	Chats   db.ChatStore
+	Planner *ollama.Planner
+}
+
+// NewAssistant returns an Assistant backed by the given resume graph and chat store.
+func NewAssistant(resume db.ResumeRepository, chats db.ChatStore) *Assistant {
+	return &Assistant{Resume: resume, Chats: chats, Planner: ollama.NewPlanner(resume)}
+}
+
+// Answer is the outcome of SmartQuery along with what led to it.
+type Answer struct {
+	Reply          string
+	RewrittenQuery string        // standalone form of a follow-up question, if rewritten
+	PlanTrace      *db.PlanTrace // how the graph plan was produced
+	Sources        []db.Source   // graph nodes the reply cites
+
+	offered []db.Source // every source given to the model as context
+}
+
+// SmartQuery answers a question within a user's conversation; an empty
+// conversationID uses the messages sent outside any conversation as memory.
+func (a *Assistant) SmartQuery(ctx context.Context, userID, conversationID, userInput string) (Answer, error) {
+	messages, answer, err := a.prepareAnswer(ctx, userID, conversationID, userInput)
+	if err != nil || messages == nil {
+		return answer, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// Step 7: Generate response from the answer provider
+	answer.Reply, err = CallOpenAI(ctx, messages)
+	answer.Sources = citedSources(answer.Reply, answer.offered)
+	answer.Reply = stripCitations(answer.Reply)
+	return answer, err
+}
+
+// SmartQueryStream runs the same pipeline as SmartQuery but streams the answer
+// through onToken. The returned Reply holds the text generated so far, also on
+// cancellation.
+func (a *Assistant) SmartQueryStream(ctx context.Context, userID, conversationID, userInput string, onToken llm.TokenFunc) (Answer, error) {
+	messages, answer, err := a.prepareAnswer(ctx, userID, conversationID, userInput)
 	if err != nil {
-		return "", err
+		return answer, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OpenAI error: %s", body)
+	if messages == nil {
+		return answer, onToken(answer.Reply)
 	}
 
-	var apiResp OpenAIChatResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", err
+	// Step 7: Stream response from the answer provider, without citation tags
+	filter := &citationFilter{onToken: onToken}
+	answer.Reply, err = llm.ForRole(ctx, llm.RoleAnswerer).ChatStream(ctx, toLLMMessages(messages), filter.Write)
+	if flushErr := filter.Flush(); err == nil {
+		err = flushErr
 	}
-
-	return apiResp.Choices[0].Message.Content, nil
+	answer.Sources = citedSources(answer.Reply, answer.offered)
+	answer.Reply = stripCitations(answer.Reply)
+	return answer, err
 }
 
-// ─────────────────────────────────────────────────────────────────────────────
-// SmartQuery: Main entry for user Q&A using Neo4j and OpenAI
-// ─────────────────────────────────────────────────────────────────────────────
-
-func SmartQuery(userID, userInput string) (string, error) {
+// prepareAnswer plans the graph query and builds the answer prompt. For casual
+// input it returns nil messages and an Answer holding a canned reply instead.
+func (a *Assistant) prepareAnswer(ctx context.Context, userID, conversationID, userInput string) ([]db.ChatMessage, Answer, error) {
 	if isNonQuery(userInput) {
-		return "Hey there! Feel free to ask me anything about my work experience, skills, or projects. 😊", nil
+		return nil, Answer{Reply: "Hey there! Feel free to ask me anything about my work experience, skills, or projects. 😊"}, nil
 	}
 
-	// Step 1: Ask Ollama to plan a query
-	plan, err := ollama.PlanGraphQuery(userInput)
+	// Step 1: Load recent conversation so follow-ups keep their referent
+	memory, err := a.LoadConversationMemory(ctx, userID, conversationID)
 	if err != nil {
-		return "", fmt.Errorf("failed to plan graph query: %w", err)
+		log.Printf("[WARN] Continuing without conversation memory: %v", err)
 	}
+
+	// Step 2: Rewrite pronoun-heavy follow-ups into a standalone question
+	var answer Answer
+	question := userInput
+	if rewritten := RewriteQuery(ctx, userInput, memory); rewritten != "" {
+		answer.RewrittenQuery = rewritten
+		question = rewritten
+	}
+
+	// Step 3: Ask Ollama to plan a query
+	plan, err := a.Planner.PlanGraphQuery(ctx, question, memory.Transcript())
+	if err != nil {
+		return nil, answer, fmt.Errorf("failed to plan graph query: %w", err)
+	}
+	answer.PlanTrace = &plan.Trace
 	log.Println("plan:", plan)
 
 	// Special case: strip redundant HAS_TAG on "Hackathon" hobby
@@ -129,52 +162,34 @@ func SmartQuery(userID, userInput string) (string, error) {
 		}
 	}
 
-	// Step 2: Build graph-based context
-	context, err := BuildContextFromGraphPlan(plan)
+	// Step 4: Build graph-based context
+	graphContext, err := a.BuildContextFromGraphPlan(ctx, plan)
 	if err != nil {
-		return "", fmt.Errorf("failed to build context from graph plan: %w", err)
+		return nil, answer, fmt.Errorf("failed to build context from graph plan: %w", err)
+	}
+	answer.offered = graphContext.Sources
+	answer.PlanTrace.ContextTruncated = graphContext.Budget.Truncated
+	answer.PlanTrace.ContextDropped = graphContext.Budget.Dropped
+	log.Println("context:", graphContext.Text)
+
+	// Step 5: Create user prompt
+	if answer.RewrittenQuery != "" {
+		userInput = fmt.Sprintf("%s\n(Interpreted as: %s)", userInput, answer.RewrittenQuery)
 	}
-	log.Println("context:", context)
-
-	// Step 3: Create user prompt
 	userPrompt := fmt.Sprintf(`Relevant Resume Info:
 %s
 
+%s
+
 User Question:
-%s`, context, userInput)
+%s`, graphContext.Text, citationInstructions, userInput)
 
-	systemPrompt := BuildPersonaSystemPrompt()
+	systemPrompt := a.BuildPersonaSystemPrompt(ctx)
 	log.Println("prompt:", userPrompt)
 
-	// Step 4: Format messages
-	messages := []db.ChatMessage{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userPrompt},
-	}
-
-	// Step 5: Generate response from OpenAI
-	return CallOpenAI(messages, "gpt-3.5-turbo")
-}
-
-// ─────────────────────────────────────────────────────────────────────────────
-// Persona Prompt
-// ─────────────────────────────────────────────────────────────────────────────
-
-func BuildPersonaSystemPrompt() string {
-	return `
-You are Gabriella — a Lebanese-Greek software engineer and cybersecurity researcher based in Canada.
-
-You're energetic, bubbly, and passionate about solving technical challenges. You bring the same competitive energy from your love of gaming (especially Riot Games) into your work.
-
-Speak strictly in the first person — use "I", "me", and "my" naturally. Keep your tone youthful, humble, and confident. Avoid sounding like you're reciting a resume or repeating facts word-for-word. Instead, answer conversationally — like you're talking to someone who's curious about your story.
-
-Don't say "As Gabriella". 
-
-"Share the essence of who you are and what you've done in 2–5 short, engaging sentences."
-
-If someone asks something vague or off-topic, it's okay to say:
-"I'm not sure how to answer that one — but feel free to ask about my projects, skills, or experience!"
-
-If using one example, use the most impressive example in the sense of complex tech used.
-`
+	// Step 6: Format messages, with past turns between persona and question
+	messages := []db.ChatMessage{{Role: "system", Content: systemPrompt}}
+	messages = append(messages, memory.Messages()...)
+	messages = append(messages, db.ChatMessage{Role: "user", Content: userPrompt})
+	return messages, answer, nil
 }
@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/tenant"
+)
+
+// fixture is a small resume: two projects, one of them tagged, and the
+// person they belong to.
+var fixture = db.Export{
+	Version: 1,
+	Graph: db.ResumeGraph{
+		Person: &db.Person{ID: "me", Name: "Ada Example", Summary: "Backend engineer"},
+		Projects: []db.Project{
+			{ID: "p1", Name: "Atlas", Description: "A map tile server written in Go", GitHub: "https://github.com/example/atlas"},
+			{ID: "p2", Name: "Beacon", Description: "A status page for small teams"},
+		},
+		Tags: []db.Tag{{Name: "Backend"}},
+		Relationships: []db.Relationship{
+			{Type: "HAS_TAG", From: db.NodeRef{Label: "Project", Key: "p1"}, To: db.NodeRef{Label: "Tag", Key: "Backend"}},
+		},
+	},
+}
+
+func TestSmartQueryRewritesPlansAndCites(t *testing.T) {
+	t.Setenv("PLANNER_FASTPATH_ENABLED", "false")
+
+	planner := &llm.Fake{ModelName: "fake-planner", Rules: []llm.FakeRule{
+		{Match: "STANDALONE QUESTION:", Reply: "What is the Atlas project written in?"},
+		{Match: "graph planner", Reply: `{"target_nodes": ["Project"], "filters": [{"on": "Name", "value": "Atlas"}], "reasoning": "asks about Atlas"}`},
+	}}
+	answerer := &llm.Fake{ModelName: "fake-answerer", Rules: []llm.FakeRule{
+		{Match: "Relevant Resume Info", Reply: "Atlas is written in Go [Project:p1]."},
+	}}
+	llm.Use(llm.RolePlanner, planner)
+	llm.Use(llm.RoleAnswerer, answerer)
+	t.Cleanup(func() {
+		llm.Use(llm.RolePlanner, nil)
+		llm.Use(llm.RoleAnswerer, nil)
+	})
+
+	ctx := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "test"})
+	chats := db.NewMemoryChatStore()
+	for _, msg := range []db.ChatMessage{
+		{Role: "user", Content: "Tell me about Atlas", ConversationID: "c1"},
+		{Role: "assistant", Content: "Atlas serves map tiles.", ConversationID: "c1"},
+	} {
+		if _, err := chats.StoreMessage(ctx, "u1", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := NewAssistant(db.NewMemoryRepository(fixture), chats)
+
+	answer, err := a.SmartQuery(ctx, "u1", "c1", "What is it written in?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if answer.RewrittenQuery != "What is the Atlas project written in?" {
+		t.Errorf("RewrittenQuery = %q", answer.RewrittenQuery)
+	}
+
+	trace := answer.PlanTrace
+	if trace == nil {
+		t.Fatal("no plan trace")
+	}
+	if trace.Planner != db.PlannerLLM || trace.Outcome != db.PlanValid || trace.Attempts != 1 {
+		t.Errorf("trace = planner %q, outcome %q after %d attempt(s); want the LLM plan valid at once", trace.Planner, trace.Outcome, trace.Attempts)
+	}
+	if trace.Reasoning != "asks about Atlas" {
+		t.Errorf("trace reasoning = %q", trace.Reasoning)
+	}
+	if !strings.Contains(lastCall(t, planner), "QUESTION:\nWhat is the Atlas project written in?") {
+		t.Error("planner was not asked the rewritten question")
+	}
+
+	prompt := lastCall(t, answerer)
+	if !strings.Contains(prompt, "- [Project:p1] Atlas: A map tile server written in Go") {
+		t.Errorf("context lacks the tagged Atlas project:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Beacon") {
+		t.Errorf("context includes Beacon, which neither the plan nor the question matches:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "(Interpreted as: What is the Atlas project written in?)") {
+		t.Errorf("prompt lacks the rewritten question:\n%s", prompt)
+	}
+
+	if answer.Reply != "Atlas is written in Go." {
+		t.Errorf("Reply = %q", answer.Reply)
+	}
+	want := db.Source{ID: "p1", Type: "Project", Name: "Atlas", Link: "https://github.com/example/atlas"}
+	if len(answer.Sources) != 1 || answer.Sources[0] != want {
+		t.Errorf("Sources = %+v, want [%+v]", answer.Sources, want)
+	}
+}
+
+// lastCall returns the last prompt a Fake was called with.
+func lastCall(t *testing.T, f *llm.Fake) string {
+	t.Helper()
+	if len(f.Calls) == 0 {
+		t.Fatalf("%s was never called", f.ModelName)
+	}
+	return f.Calls[len(f.Calls)-1]
+}
@@ -0,0 +1,170 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go-ai/db"
+	"go-ai/tenant"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// defaultPersonaTemplate renders the system prompt from the Person node. Set a
+// tenant's personaTemplateFile (PERSONA_TEMPLATE_FILE for the default tenant)
+// to use a different one; it receives a PersonaData.
+const defaultPersonaTemplate = `
+You are {{.Name}}{{with .Summary}} — {{.}}{{end}}{{with .Location}}, based in {{.}}{{end}}.{{with .Pronouns}}
+Your pronouns are {{.}}.{{end}}{{with .Background}}
+Your background: {{join . ", "}}.{{end}}{{with .VoiceTone}}
+Your voice and tone: {{.}}.{{end}}
+
+Speak strictly in the first person — use "I", "me", and "my" naturally. Don't say "As {{.Name}}".
+{{if .Rules}}
+RULES:
+{{range .Rules}}- {{.}}
+{{end}}{{end}}`
+
+// defaultPersonaRules apply when the tenant has no rules file.
+var defaultPersonaRules = []string{
+	"Answer conversationally, like you're talking to someone who's curious about your story. Avoid reciting your resume or repeating facts word-for-word.",
+	"Share the essence of who you are and what you've done in 2–5 short, engaging sentences.",
+	`If someone asks something vague or off-topic, it's okay to say: "I'm not sure how to answer that one — but feel free to ask about my projects, skills, or experience!"`,
+	"If using one example, use the most impressive one in the sense of complex tech used.",
+}
+
+// fallbackPersonaName is used when no Person node can be loaded.
+const fallbackPersonaName = "the owner of this portfolio"
+
+// PersonaData is what the persona template is rendered with.
+type PersonaData struct {
+	db.Person
+	Rules []string
+}
+
+// loadedPersona is a tenant's parsed persona template and rules.
+type loadedPersona struct {
+	template *template.Template
+	rules    []string
+}
+
+// personas caches each tenant's loadedPersona by tenant ID.
+var personas sync.Map
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Persona Prompt
+// ─────────────────────────────────────────────────────────────────────────────
+
+// BuildPersonaSystemPrompt renders the persona template with the Person node
+// from the graph and the configured rules.
+func (a *Assistant) BuildPersonaSystemPrompt(ctx context.Context) string {
+	tmpl, rules := loadPersona(tenant.FromContext(ctx))
+
+	data := PersonaData{Rules: rules}
+	if person, err := a.Resume.GetPerson(ctx); err != nil {
+		log.Printf("[WARN] Rendering persona without a Person node: %v", err)
+	} else {
+		data.Person = *person
+	}
+	if data.Name == "" {
+		data.Name = fallbackPersonaName
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		log.Printf("[WARN] Persona template failed, using the default: %v", err)
+		b.Reset()
+		_ = parsePersonaTemplate(defaultPersonaTemplate).Execute(&b, data)
+	}
+	return b.String()
+}
+
+// renderPerson describes the Person node for the answer context from its
+// stored fields only.
+func renderPerson(p db.Person) string {
+	var b strings.Builder
+	b.WriteString("- " + p.Name)
+	if p.Pronouns != "" {
+		b.WriteString(fmt.Sprintf(" (%s)", p.Pronouns))
+	}
+	if p.Summary != "" {
+		b.WriteString(": " + p.Summary)
+	}
+	b.WriteString("\n")
+	if p.Location != "" {
+		b.WriteString(fmt.Sprintf("  Based in %s\n", p.Location))
+	}
+	if len(p.Background) > 0 {
+		b.WriteString(fmt.Sprintf("  Background: %s\n", strings.Join(p.Background, ", ")))
+	}
+	return b.String()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Loading
+// ─────────────────────────────────────────────────────────────────────────────
+
+// loadPersona reads a tenant's template and rules files once, falling back to
+// the defaults when a file is unset or unreadable.
+func loadPersona(t *tenant.Tenant) (*template.Template, []string) {
+	if cached, ok := personas.Load(t.ID); ok {
+		p := cached.(loadedPersona)
+		return p.template, p.rules
+	}
+
+	persona := loadedPersona{template: parsePersonaTemplate(defaultPersonaTemplate)}
+	if path := t.PersonaTemplateFile; path != "" {
+		if text, err := os.ReadFile(path); err != nil {
+			log.Printf("⚠️ Could not read persona template %s: %v", path, err)
+		} else if tmpl, err := template.New("persona").Funcs(personaFuncs).Parse(string(text)); err != nil {
+			log.Printf("⚠️ Invalid persona template %s: %v", path, err)
+		} else {
+			persona.template = tmpl
+		}
+	}
+
+	persona.rules = defaultPersonaRules
+	if path := t.PersonaRulesFile; path != "" {
+		if rules, err := readRules(path); err != nil {
+			log.Printf("⚠️ Could not read persona rules %s: %v", path, err)
+		} else {
+			persona.rules = rules
+		}
+	}
+
+	personas.Store(t.ID, persona)
+	return persona.template, persona.rules
+}
+
+var personaFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+}
+
+func parsePersonaTemplate(text string) *template.Template {
+	return template.Must(template.New("persona").Funcs(personaFuncs).Parse(text))
+}
+
+// readRules reads one rule per line, skipping blank lines and # comments.
+func readRules(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, line)
+	}
+	return rules, scanner.Err()
+}
@@ -0,0 +1,237 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"go-ai/config"
+	"go-ai/db"
+	"go-ai/ollama"
+	"log"
+	"sort"
+	"strings"
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion (the usual 60).
+const rrfK = 60
+
+// Candidate is one resume node competing for a place in the answer context.
+type Candidate struct {
+	Label    string
+	Key      string
+	Name     string
+	Line     string // rendered context line(s) for this node
+	Featured bool
+	Link     string   // demo or repository URL, if any
+	Date     string   // end date, or start date while ongoing
+	Score    float64  // fused reciprocal rank score
+	Sources  []string // which retrievers surfaced it: planner, keyword, vector
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Ranking
+// ─────────────────────────────────────────────────────────────────────────────
+
+// RankCandidates scores nodes surfaced by the planned filters, full-text
+// matches and embedding similarity, fuses the rankings with reciprocal rank
+// fusion and returns at most limit candidates, best first. Keyword and vector
+// hits must also pass the filters, so they can't bring back what a "not" or
+// "and" clause ruled out. Target types that none of the retrievers hit fall
+// back to their featured nodes.
+func (a *Assistant) RankCandidates(ctx context.Context, plan ollama.GraphQueryPlan, question string, limit int) []Candidate {
+	targets := map[string]bool{}
+	for _, t := range plan.TargetNodes {
+		targets[t] = true
+	}
+	inScope := func(label string) bool {
+		return len(targets) == 0 || targets[label]
+	}
+
+	fused := map[string]*Candidate{}
+	var order []string
+	add := func(source string, ranked []Candidate) {
+		for rank, c := range ranked {
+			if !inScope(c.Label) {
+				continue
+			}
+			id := c.Label + ":" + c.Key
+			existing, ok := fused[id]
+			if !ok {
+				c := c
+				existing = &c
+				fused[id] = existing
+				order = append(order, id)
+			} else if source == "planner" {
+				// Planner results carry the fully rendered node, prefer them
+				existing.Line = c.Line
+				if existing.Link == "" {
+					existing.Link = c.Link
+				}
+				if existing.Date == "" {
+					existing.Date = c.Date
+				}
+			}
+			existing.Score += 1.0 / float64(rrfK+rank+1)
+			existing.Sources = append(existing.Sources, source)
+		}
+	}
+
+	// Source 1: the planner's filters, if it gave any. The keys they match
+	// per type bound the other sources.
+	matched := map[string]map[string]bool{}
+	if len(plan.Filters) > 0 {
+		for _, label := range plan.TargetNodes {
+			planned, err := a.plannedCandidates(ctx, label, plan.Filters)
+			if err != nil {
+				log.Printf("[WARN] %s query failed: %v", label, err)
+				continue
+			}
+			matched[label] = map[string]bool{}
+			for _, c := range planned {
+				matched[label][c.Key] = true
+			}
+			add("planner", planned)
+		}
+	}
+
+	// Source 2: keyword matches from the full-text index
+	if hits, err := a.Resume.FullTextSearch(ctx, question, limit*2); err != nil {
+		log.Printf("[WARN] Keyword retrieval failed: %v", err)
+	} else {
+		add("keyword", passingFilters(candidatesFromHits(hits), matched))
+	}
+
+	// Source 3: embedding similarity
+	if config.GetSemanticRetrievalEnabled() {
+		if hits, err := a.SemanticSearch(ctx, question, limit*2); err != nil {
+			log.Printf("[WARN] Semantic retrieval failed: %v", err)
+		} else {
+			add("vector", passingFilters(candidatesFromHits(hits), matched))
+		}
+	}
+
+	// Fallback: featured nodes for target types nobody matched
+	for _, label := range plan.TargetNodes {
+		if hasLabel(fused, label) {
+			continue
+		}
+		featured, err := a.plannedCandidates(ctx, label, nil)
+		if err != nil {
+			log.Printf("[WARN] %s query failed: %v", label, err)
+		}
+		add("fallback", featuredFirst(featured))
+	}
+
+	ranked := make([]Candidate, 0, len(order))
+	for _, id := range order {
+		ranked = append(ranked, *fused[id])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Featured && !ranked[j].Featured
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	for _, c := range ranked {
+		log.Printf("ranked: [%s] %s %.4f via %v", c.Label, c.Name, c.Score, c.Sources)
+	}
+	return ranked
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Retriever adapters
+// ─────────────────────────────────────────────────────────────────────────────
+
+// plannedCandidates runs the filter query for one node type and renders the
+// results in the order the query returned them.
+func (a *Assistant) plannedCandidates(ctx context.Context, label string, filters []db.FilterClause) ([]Candidate, error) {
+	var out []Candidate
+	switch label {
+	case "Project":
+		projects, err := a.Resume.FindProjectsWithFilters(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			out = append(out, Candidate{Label: label, Key: p.ID, Name: p.Name, Featured: p.Featured, Link: p.Link(), Date: db.LatestDate(p.StartDate, p.EndDate), Line: renderProject(p)})
+		}
+	case "WorkExperience":
+		experiences, err := a.Resume.FindWorkExperienceWithFilters(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range experiences {
+			out = append(out, Candidate{Label: label, Key: w.ID, Name: w.Title + " at " + w.Company, Featured: w.Featured, Date: db.LatestDate(w.StartDate, w.EndDate), Line: renderWorkExperience(w)})
+		}
+	case "Education":
+		education, err := a.Resume.FindEducationWithFilters(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range education {
+			out = append(out, Candidate{Label: label, Key: e.ID, Name: e.Degree + " at " + e.Institution, Date: db.LatestDate(e.StartDate, e.EndDate), Line: renderEducation(e)})
+		}
+	case "Hobby":
+		hobbies, err := a.Resume.FindHobbiesWithFilters(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hobbies {
+			out = append(out, Candidate{Label: label, Key: h.Name, Name: h.Name, Line: renderHobby(h)})
+		}
+	case "Skill":
+		skills, err := a.Resume.FindSkillsWithFilters(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range skills {
+			out = append(out, Candidate{Label: label, Key: s.Name, Name: s.Name, Line: renderSkill(s)})
+		}
+	}
+	return out, nil
+}
+
+// candidatesFromHits converts search hits into candidates in score order.
+func candidatesFromHits(hits []db.SimilarNode) []Candidate {
+	out := make([]Candidate, 0, len(hits))
+	for _, h := range hits {
+		line := fmt.Sprintf("- %s\n", h.Name)
+		if lines := strings.SplitN(h.Text, "\n", 2); len(lines) == 2 {
+			line = fmt.Sprintf("- %s: %s\n", h.Name, strings.ReplaceAll(lines[1], "\n", " "))
+		}
+		out = append(out, Candidate{Label: h.Label, Key: h.Key, Name: h.Name, Featured: h.Featured, Link: h.Link, Date: h.Date, Line: line})
+	}
+	return out
+}
+
+// passingFilters drops candidates of a type the planner's filters ran on that
+// the filters didn't match. Types without a filter result pass as they are.
+func passingFilters(candidates []Candidate, matched map[string]map[string]bool) []Candidate {
+	out := candidates[:0]
+	for _, c := range candidates {
+		if keys, filtered := matched[c.Label]; !filtered || keys[c.Key] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// featuredFirst moves featured candidates ahead, keeping the original order otherwise.
+func featuredFirst(candidates []Candidate) []Candidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Featured && !candidates[j].Featured
+	})
+	return candidates
+}
+
+func hasLabel(fused map[string]*Candidate, label string) bool {
+	for _, c := range fused {
+		if c.Label == label {
+			return true
+		}
+	}
+	return false
+}
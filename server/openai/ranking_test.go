@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/ollama"
+)
+
+// rankingFixture has a React project whose text matches the questions below
+// word for word, so only the filters keep it out.
+var rankingFixture = db.Export{
+	Version: 1,
+	Graph: db.ResumeGraph{
+		Projects: []db.Project{
+			{ID: "atlas", Name: "Atlas", Description: "A map tile server"},
+			{ID: "beacon", Name: "Beacon", Description: "React projects dashboard"},
+			{ID: "comet", Name: "Comet", Description: "A queue for backend jobs"},
+		},
+		Relationships: []db.Relationship{
+			{Type: "USES", From: db.NodeRef{Label: "Project", Key: "atlas"}, To: db.NodeRef{Label: "Skill", Key: "Go"}},
+			{Type: "USES", From: db.NodeRef{Label: "Project", Key: "beacon"}, To: db.NodeRef{Label: "Skill", Key: "React"}},
+			{Type: "USES", From: db.NodeRef{Label: "Project", Key: "comet"}, To: db.NodeRef{Label: "Skill", Key: "Go"}},
+			{Type: "HAS_TAG", From: db.NodeRef{Label: "Project", Key: "comet"}, To: db.NodeRef{Label: "Tag", Key: "Backend"}},
+		},
+	},
+}
+
+func TestRankCandidatesKeepsPlannedFilters(t *testing.T) {
+	a := NewAssistant(db.NewMemoryRepository(rankingFixture), db.NewMemoryChatStore())
+
+	tests := []struct {
+		name     string
+		question string
+		filters  []db.FilterClause
+		want     []string
+	}{
+		{"not", "Projects that don't use React", []db.FilterClause{{On: "Skill", Value: "React", Op: "not"}}, []string{"Atlas", "Comet"}},
+		{"and", "Backend projects like the React dashboard", []db.FilterClause{{On: "Tag", Value: "Backend"}}, []string{"Comet"}},
+		{"no filters", "React projects", nil, []string{"Beacon"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := ollama.GraphQueryPlan{TargetNodes: []string{"Project"}, Filters: tt.filters}
+			var got []string
+			for _, c := range a.RankCandidates(context.Background(), plan, tt.question, 10) {
+				got = append(got, c.Name)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ranked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
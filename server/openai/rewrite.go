@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"go-ai/llm"
+	"log"
+	"strings"
+)
+
+// referenceWords are words that usually point back at something said earlier.
+var referenceWords = map[string]bool{
+	"it": true, "its": true, "that": true, "this": true, "those": true, "these": true,
+	"they": true, "them": true, "their": true, "there": true, "one": true, "ones": true,
+	"first": true, "second": true, "third": true, "last": true, "previous": true,
+	"same": true, "former": true, "latter": true,
+}
+
+// followUpPrefixes mark questions that continue the previous one.
+var followUpPrefixes = []string{"and ", "what about", "how about", "also", "tell me more", "more about"}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Detection
+// ─────────────────────────────────────────────────────────────────────────────
+
+// needsRewrite reports whether the question likely depends on earlier turns.
+func needsRewrite(input string, memory ConversationMemory) bool {
+	if len(memory.Turns) == 0 && memory.Summary == "" {
+		return false
+	}
+
+	lower := strings.TrimSpace(strings.ToLower(input))
+	for _, p := range followUpPrefixes {
+		if strings.HasPrefix(lower, p) {
+			return true
+		}
+	}
+	for _, word := range strings.Fields(lower) {
+		if referenceWords[strings.Trim(word, ".,!?;:\"'()")] {
+			return true
+		}
+	}
+	return false
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Rewriting
+// ─────────────────────────────────────────────────────────────────────────────
+
+// RewriteQuery turns a follow-up into a standalone question using the recent
+// conversation. It returns "" when no rewrite is needed or the model fails.
+func RewriteQuery(ctx context.Context, input string, memory ConversationMemory) string {
+	if !needsRewrite(input, memory) {
+		return ""
+	}
+
+	prompt := fmt.Sprintf(`Rewrite the follow-up question so it can be understood without the conversation.
+Replace pronouns and vague references ("it", "that one", "the second company") with the names they refer to.
+If the question is already standalone, return it unchanged.
+Output only the rewritten question on a single line.
+
+CONVERSATION:
+%s
+
+FOLLOW-UP QUESTION:
+%s
+
+STANDALONE QUESTION:`, memory.Transcript(), input)
+
+	raw, err := llm.ForRole(ctx, llm.RolePlanner).Generate(ctx, prompt)
+	if err != nil {
+		log.Printf("[WARN] Query rewrite failed, using original question: %v", err)
+		return ""
+	}
+
+	rewritten := cleanRewrite(raw)
+	if rewritten == "" || strings.EqualFold(rewritten, strings.TrimSpace(input)) {
+		return ""
+	}
+	log.Printf("rewrote query: %q → %q", input, rewritten)
+	return rewritten
+}
+
+// cleanRewrite keeps the first non-empty line and strips quotes and labels.
+func cleanRewrite(raw string) string {
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		line = strings.TrimPrefix(line, "STANDALONE QUESTION:")
+		return strings.Trim(strings.TrimSpace(line), `"'`)
+	}
+	return ""
+}
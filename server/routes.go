@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
-	"go-ai/config"
 	"go-ai/db"
 	"go-ai/openai"
+	"go-ai/session"
+	"go-ai/tenant"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,42 +23,56 @@ import (
 // Request/Response Types
 // ─────────────────────────────────────────────────────────────────────────────
 
+// ChatRequest is a question from a visitor. UserID is optional; when sent it
+// must match the visitor's session. Without a conversationId the question goes
+// to the visitor's messages outside any conversation.
 type ChatRequest struct {
-	UserID  string `json:"userId"`
-	Message string `json:"content"`
+	UserID         string `json:"userId"`
+	ConversationID string `json:"conversationId,omitempty"`
+	Message        string `json:"content"`
 }
 
 type ChatResponse struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	ConversationID string      `json:"conversationId,omitempty"`
+	Role           string      `json:"role"`
+	Content        string      `json:"content"`
+	Sources        []db.Source `json:"sources"`
+}
+
+// server holds what the handlers depend on.
+type server struct {
+	assistant *openai.Assistant
+	// editable is set when the resume graph lives in Neo4j, which /admin writes to
+	editable bool
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
 // POST /chat — handles user input and returns GPT response
 // ─────────────────────────────────────────────────────────────────────────────
 
-func chatHandler(w http.ResponseWriter, r *http.Request) {
-	var req ChatRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+func (s *server) chatHandler(w http.ResponseWriter, r *http.Request) {
+	req, conv, ok := s.decodeChatRequest(w, r)
+	if !ok {
 		return
 	}
 
-	reply, err := openai.SmartQuery(req.UserID, req.Message)
+	answer, err := s.assistant.SmartQuery(r.Context(), req.UserID, req.ConversationID, req.Message)
 	if err != nil {
 		http.Error(w, "Failed to generate response: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if err := storeChatPair(req.UserID, req.Message, reply); err != nil {
+	if _, err := s.storeChatPair(context.WithoutCancel(r.Context()), conv, req, answer); err != nil {
 		http.Error(w, "Failed to store chat messages: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(ChatResponse{
-		Role:    "assistant",
-		Content: reply,
+		ConversationID: req.ConversationID,
+		Role:           "assistant",
+		Content:        answer.Reply,
+		Sources:        nonNilSources(answer.Sources),
 	}); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
@@ -63,83 +80,255 @@ func chatHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
-// GET /chat?userId=... — fetches past chat messages
+// POST /chat/stream — streams the GPT response as Server-Sent Events
 // ─────────────────────────────────────────────────────────────────────────────
 
-func handleGetChat(w http.ResponseWriter, r *http.Request) {
-	userId := r.URL.Query().Get("userId")
-	if userId == "" {
-		http.Error(w, "Missing userId", http.StatusBadRequest)
+type streamToken struct {
+	Content string `json:"content"`
+}
+
+type streamDone struct {
+	ID             string      `json:"id"`
+	ConversationID string      `json:"conversationId,omitempty"`
+	Role           string      `json:"role"`
+	Content        string      `json:"content"`
+	Sources        []db.Source `json:"sources"`
+}
+
+type streamError struct {
+	Error string `json:"error"`
+}
+
+func (s *server) chatStreamHandler(w http.ResponseWriter, r *http.Request) {
+	req, conv, ok := s.decodeChatRequest(w, r)
+	if !ok {
 		return
 	}
 
-	messages, err := db.GetMessages(userId)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	answer, streamErr := s.assistant.SmartQueryStream(r.Context(), req.UserID, req.ConversationID, req.Message, func(token string) error {
+		return writeSSE(w, flusher, "token", streamToken{Content: token})
+	})
+
+	// Persist whatever was generated, even if the client went away mid-stream
+	var id string
+	if answer.Reply != "" {
+		var err error
+		if id, err = s.storeChatPair(context.WithoutCancel(r.Context()), conv, req, answer); err != nil {
+			log.Printf("[ERROR] Failed to store streamed chat messages: %v", err)
+			_ = writeSSE(w, flusher, "error", streamError{Error: "Failed to store chat messages"})
+			return
+		}
+	}
+
+	switch {
+	case streamErr == nil:
+		_ = writeSSE(w, flusher, "done", streamDone{ID: id, ConversationID: req.ConversationID, Role: "assistant", Content: answer.Reply, Sources: nonNilSources(answer.Sources)})
+	case r.Context().Err() == nil:
+		log.Printf("[ERROR] Streaming response failed: %v", streamErr)
+		_ = writeSSE(w, flusher, "error", streamError{Error: "Failed to generate response: " + streamErr.Error()})
+	}
+}
+
+// decodeChatRequest decodes a ChatRequest and looks up its conversation,
+// answering 404 when the user has no such conversation.
+func (s *server) decodeChatRequest(w http.ResponseWriter, r *http.Request) (ChatRequest, *db.Conversation, bool) {
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, nil, false
+	}
+	var ok bool
+	if req.UserID, ok = requestUser(w, r, req.UserID); !ok {
+		return req, nil, false
+	}
+	if req.ConversationID == "" {
+		return req, nil, true
+	}
+
+	conv, err := s.assistant.Chats.GetConversation(r.Context(), req.UserID, req.ConversationID)
 	if err != nil {
-		http.Error(w, "Failed to fetch messages", http.StatusInternalServerError)
+		writeChatStoreError(w, "load conversation", err)
+		return req, nil, false
+	}
+	return req, &conv, true
+}
+
+// writeSSE writes one named Server-Sent Event with a JSON payload and flushes it.
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// GET /chat?before=...&after=...&limit=... — pages through past
+// chat messages. Without cursors it returns the latest page; before=<id>
+// pages back from a message and after=<id> fetches what followed it.
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (s *server) handleGetChat(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userId, ok := requestUser(w, r, query.Get("userId"))
+	if !ok {
 		return
 	}
-	if messages == nil {
-		messages = []db.ChatMessage{}
+	limit, ok := queryInt(w, query.Get("limit"), "limit", defaultMessagePageSize)
+	if !ok {
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(messages); err != nil {
-		http.Error(w, "Failed to encode messages", http.StatusInternalServerError)
+	page, err := s.assistant.Chats.GetHistory(r.Context(), userId, db.HistoryQuery{
+		Before: query.Get("before"),
+		After:  query.Get("after"),
+		Limit:  min(max(limit, 1), maxMessagePageSize),
+	})
+	if err != nil {
+		writeChatStoreError(w, "fetch messages", err)
 		return
 	}
+	writeJSON(w, http.StatusOK, page)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
 // Internal: Store both user + assistant message to DB
 // ─────────────────────────────────────────────────────────────────────────────
 
-func storeChatPair(userId, userMsg, assistantMsg string) error {
+// storeChatPair stores the exchange and returns the assistant message ID. The
+// rewritten question and plan trace are kept on the user message for auditing.
+// A conversation without a title is named after its first question.
+func (s *server) storeChatPair(ctx context.Context, conv *db.Conversation, req ChatRequest, answer openai.Answer) (string, error) {
 	now := time.Now()
+	var assistantID string
 	for _, msg := range []db.ChatMessage{
-		{UserID: userId, Role: "user", Content: userMsg, Timestamp: now},
-		{UserID: userId, Role: "assistant", Content: assistantMsg, Timestamp: now},
+		{UserID: req.UserID, ConversationID: req.ConversationID, Role: "user", Content: req.Message, Timestamp: now, RewrittenQuery: answer.RewrittenQuery, PlanTrace: answer.PlanTrace},
+		{UserID: req.UserID, ConversationID: req.ConversationID, Role: "assistant", Content: answer.Reply, Timestamp: now, Sources: answer.Sources},
 	} {
-		if err := db.StoreMessage(userId, msg); err != nil {
-			return err
+		id, err := s.assistant.Chats.StoreMessage(ctx, req.UserID, msg)
+		if err != nil {
+			return "", err
 		}
+		assistantID = id
+	}
+
+	if conv != nil && conv.Title == "" {
+		if _, err := s.assistant.Chats.RenameConversation(ctx, req.UserID, conv.ID, titleFromQuestion(req.Message)); err != nil {
+			log.Printf("[WARN] Failed to title conversation %s: %v", conv.ID, err)
+		}
+	}
+	return assistantID, nil
+}
+
+// writeJSON encodes v as the response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[ERROR] Failed to encode response: %v", err)
 	}
-	return nil
 }
 
-// Host check middleware
-func hostCheckMiddleware(allowedHost string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			fmt.Println("Host header received:", r.Host)
-			if r.Host != allowedHost {
-				http.Error(w, "Forbidden", http.StatusForbidden)
-				return
-			}
-			next.ServeHTTP(w, r)
-		})
+// nonNilSources makes replies without sources encode as [] rather than null.
+func nonNilSources(sources []db.Source) []db.Source {
+	if sources == nil {
+		return []db.Source{}
 	}
+	return sources
+}
+
+// Tenant middleware: resolves the tenant by API key or Host header and scopes
+// the request context to it. Unknown hosts and keys are rejected.
+func tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := tenant.Resolve(r)
+		if !ok {
+			fmt.Println("No tenant for host:", r.Host)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), t)))
+	})
+}
+
+// Session middleware: identifies the visitor by their signed session cookie,
+// issuing a new anonymous session when it is missing or doesn't verify.
+func sessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := tenant.FromContext(r.Context())
+		var userID string
+		var ok bool
+		if cookie, err := r.Cookie(session.CookieName); err == nil {
+			userID, ok = session.Verify(t.ID, cookie.Value)
+		}
+		if !ok {
+			userID = session.NewUserID()
+			secure := r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+			http.SetCookie(w, session.Cookie(session.Sign(t.ID, userID), secure))
+		}
+		next.ServeHTTP(w, r.WithContext(session.NewContext(r.Context(), userID)))
+	})
+}
+
+// requestUser returns the user ID of the visitor's session. A userId the
+// client sends as well must be the same one, so nobody can act as another
+// visitor by guessing their ID.
+func requestUser(w http.ResponseWriter, r *http.Request, claimed string) (string, bool) {
+	userID, ok := session.UserID(r.Context())
+	if !ok {
+		http.Error(w, "Missing session", http.StatusUnauthorized)
+		return "", false
+	}
+	if claimed != "" && claimed != userID {
+		http.Error(w, "userId does not match the session", http.StatusForbidden)
+		return "", false
+	}
+	return userID, true
+}
+
+// allowTenantOrigin lets each tenant's frontend call the API cross-origin.
+func allowTenantOrigin(r *http.Request, origin string) bool {
+	t, ok := tenant.Resolve(r)
+	return ok && t.AllowsOrigin(origin)
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
 // RegisterRoutes sets up HTTP routes and middleware
 // ─────────────────────────────────────────────────────────────────────────────
 
-func RegisterRoutes() http.Handler {
+func RegisterRoutes(s *server) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware stack
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{config.GetFrontendOrigin()},
-		AllowedMethods:   []string{"GET", "POST"},
-		AllowedHeaders:   []string{"Accept", "Content-Type"},
+		AllowOriginFunc:  allowTenantOrigin,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
 		AllowCredentials: true,
 	}))
 	// Rate limit: 20 requests per minute per IP
 	r.Use(httprate.LimitByIP(20, 1*time.Minute))
-	// Host protection: only allow api.luxscious.dev
-	r.Use(hostCheckMiddleware("api.luxscious.dev"))
+	// Host protection: only serve hosts and API keys of a configured tenant
+	r.Use(tenantMiddleware)
 
 	// Routes
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -148,8 +337,20 @@ func RegisterRoutes() http.Handler {
 			fmt.Println("Failed to write response:", err)
 		}
 	})
-	r.Get("/chat", handleGetChat)
-	r.Post("/chat", chatHandler)
+	r.Group(func(r chi.Router) {
+		// Visitors act as the user their session cookie names
+		r.Use(sessionMiddleware)
+
+		r.Get("/chat", s.handleGetChat)
+		r.Post("/chat", s.chatHandler)
+		r.Post("/chat/stream", s.chatStreamHandler)
+		r.Delete("/chat", s.handleDeleteChat)
+		r.Get("/chat/export", s.handleExportChat)
+		r.Route("/conversations", s.conversationRoutes)
+	})
+	if s.editable {
+		r.Route("/admin", s.adminRoutes)
+	}
 
 	return r
 }
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-ai/db"
+	"go-ai/llm"
+	"go-ai/openai"
+	"go-ai/session"
+	"go-ai/tenant"
+)
+
+// testResume is the graph the test server answers from.
+var testResume = db.Export{
+	Version: 1,
+	Graph: db.ResumeGraph{
+		Person:   &db.Person{ID: "me", Name: "Ada Example"},
+		Projects: []db.Project{{ID: "p1", Name: "Atlas", Description: "A map tile server"}},
+	},
+}
+
+// testServer is the API of a single tenant on example.com, the host
+// httptest requests are sent to, with a memory chat store and fake models.
+type testServer struct {
+	handler http.Handler
+	chats   *db.MemoryChatStore
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	tenants := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(tenants, []byte(`[{"id": "test", "hosts": ["example.com"], "chatCollection": "chats_test"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TENANTS_FILE", tenants)
+	t.Setenv("SESSION_SECRET", strings.Repeat("s", minSessionSecretLength))
+	if err := tenant.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	llm.Use(llm.RolePlanner, llm.NewFake("fake-planner"))
+	llm.Use(llm.RoleAnswerer, &llm.Fake{ModelName: "fake-answerer", Rules: []llm.FakeRule{
+		{Match: "Relevant Resume Info", Reply: "I built Atlas [Project:p1]."},
+	}})
+	t.Cleanup(func() {
+		llm.Use(llm.RolePlanner, nil)
+		llm.Use(llm.RoleAnswerer, nil)
+	})
+
+	chats := db.NewMemoryChatStore()
+	s := &server{assistant: openai.NewAssistant(db.NewMemoryRepository(testResume), cancellableChatStore{chats})}
+	return &testServer{handler: RegisterRoutes(s), chats: chats}
+}
+
+// cancellableChatStore fails writes on a cancelled context, as the Mongo
+// driver does, which the memory store alone doesn't.
+type cancellableChatStore struct {
+	*db.MemoryChatStore
+}
+
+func (s cancellableChatStore) StoreMessage(ctx context.Context, userID string, msg db.ChatMessage) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.MemoryChatStore.StoreMessage(ctx, userID, msg)
+}
+
+// visitor sends requests with the session cookie the server issued it.
+type visitor struct {
+	t      *testing.T
+	server *testServer
+	cookie *http.Cookie
+}
+
+// visitor returns a new visitor, without a session until its first request.
+func (ts *testServer) visitor(t *testing.T) *visitor {
+	return &visitor{t: t, server: ts}
+}
+
+// do sends a request with an optional JSON body and keeps the session cookie.
+func (v *visitor) do(method, path string, body any) *httptest.ResponseRecorder {
+	v.t.Helper()
+	var req *http.Request
+	if body == nil {
+		req = httptest.NewRequest(method, path, nil)
+	} else {
+		data, err := json.Marshal(body)
+		if err != nil {
+			v.t.Fatal(err)
+		}
+		req = httptest.NewRequest(method, path, strings.NewReader(string(data)))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if v.cookie != nil {
+		req.AddCookie(v.cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	v.server.handler.ServeHTTP(rec, req)
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == session.CookieName {
+			v.cookie = c
+		}
+	}
+	return rec
+}
+
+// userID returns the user the visitor's session names, and a context scoped
+// to the tenant for seeding the chat store directly.
+func (v *visitor) userID() (string, context.Context) {
+	v.t.Helper()
+	if v.cookie == nil {
+		v.do(http.MethodGet, "/chat", nil)
+	}
+	t := tenant.All()[0]
+	userID, ok := session.Verify(t.ID, v.cookie.Value)
+	if !ok {
+		v.t.Fatal("session cookie does not verify")
+	}
+	return userID, tenant.NewContext(context.Background(), t)
+}
+
+// decode parses a JSON response with the expected status into v.
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+	var v T
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestPostChat(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.visitor(t)
+
+	reply := decode[ChatResponse](t, v.do(http.MethodPost, "/chat", ChatRequest{Message: "Tell me about Atlas"}), http.StatusOK)
+	if reply.Role != "assistant" || reply.Content != "I built Atlas." {
+		t.Errorf("reply = %+v", reply)
+	}
+	if len(reply.Sources) != 1 || reply.Sources[0].ID != "p1" {
+		t.Errorf("sources = %+v, want Atlas", reply.Sources)
+	}
+	if v.cookie == nil {
+		t.Fatal("no session cookie issued")
+	}
+
+	page := decode[db.HistoryPage](t, v.do(http.MethodGet, "/chat", nil), http.StatusOK)
+	if len(page.Messages) != 2 || page.Messages[0].Content != "Tell me about Atlas" || page.Messages[1].Content != "I built Atlas." {
+		t.Errorf("stored messages = %+v", page.Messages)
+	}
+
+	if rec := v.do(http.MethodPost, "/chat", ChatRequest{UserID: "someone-else", Message: "Tell me about Atlas"}); rec.Code != http.StatusForbidden {
+		t.Errorf("claiming another userId: status %d, want 403", rec.Code)
+	}
+	if rec := v.do(http.MethodPost, "/chat", "not a request"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid body: status %d, want 400", rec.Code)
+	}
+	if rec := v.do(http.MethodPost, "/chat", ChatRequest{ConversationID: "missing", Message: "Tell me about Atlas"}); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown conversation: status %d, want 404", rec.Code)
+	}
+
+	other := decode[db.HistoryPage](t, ts.visitor(t).do(http.MethodGet, "/chat", nil), http.StatusOK)
+	if len(other.Messages) != 0 {
+		t.Errorf("another visitor sees %d messages", len(other.Messages))
+	}
+}
+
+func TestGetChatPages(t *testing.T) {
+	v := newTestServer(t).visitor(t)
+	userID, ctx := v.userID()
+	var ids []string
+	for _, content := range []string{"m1", "m2", "m3", "m4", "m5"} {
+		id, err := v.server.chats.StoreMessage(ctx, userID, db.ChatMessage{Role: "user", Content: content})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		query   string
+		want    []string
+		hasMore bool
+	}{
+		{"?limit=2", []string{"m4", "m5"}, true},
+		{"?limit=2&before=" + ids[3], []string{"m2", "m3"}, true},
+		{"?limit=2&before=" + ids[1], []string{"m1"}, false},
+		{"?limit=2&after=" + ids[1], []string{"m3", "m4"}, true},
+		{"?limit=5&after=" + ids[2], []string{"m4", "m5"}, false},
+		{"?after=" + ids[0] + "&before=" + ids[3], []string{"m2", "m3"}, false},
+		{"?limit=0", []string{"m5"}, true},
+	}
+	for _, tt := range tests {
+		page := decode[db.HistoryPage](t, v.do(http.MethodGet, "/chat"+tt.query, nil), http.StatusOK)
+		var got []string
+		for _, msg := range page.Messages {
+			got = append(got, msg.Content)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || page.HasMore != tt.hasMore {
+			t.Errorf("GET /chat%s = %v (hasMore %t), want %v (hasMore %t)", tt.query, got, page.HasMore, tt.want, tt.hasMore)
+		}
+	}
+
+	if rec := v.do(http.MethodGet, "/chat?before=000000000000000000000000", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown cursor: status %d, want 404", rec.Code)
+	}
+	if rec := v.do(http.MethodGet, "/chat?limit=-1", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative limit: status %d, want 400", rec.Code)
+	}
+}
+
+// cancelOnToken is a streaming response writer that cancels the request after
+// the first token event, as a visitor closing the tab would.
+type cancelOnToken struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func (w *cancelOnToken) Write(b []byte) (int, error) {
+	if strings.HasPrefix(string(b), "event: token") {
+		w.once.Do(w.cancel)
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestChatStreamStoresCancelledExchange(t *testing.T) {
+	v := newTestServer(t).visitor(t)
+	userID, ctx := v.userID()
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/chat/stream", strings.NewReader(`{"content": "Tell me about Atlas"}`)).WithContext(reqCtx)
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(v.cookie)
+	rec := &cancelOnToken{ResponseRecorder: httptest.NewRecorder(), cancel: cancel}
+	v.server.handler.ServeHTTP(rec, req)
+
+	if reqCtx.Err() == nil {
+		t.Fatal("the stream finished without being cancelled")
+	}
+	if strings.Contains(rec.Body.String(), "event: error") {
+		t.Errorf("stream reported an error:\n%s", rec.Body)
+	}
+	page, err := v.server.chats.GetMessages(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Content != "Tell me about Atlas" || page[1].Role != "assistant" || page[1].Content == "" || page[1].Content == "I built Atlas." {
+		t.Errorf("stored messages = %+v, want the question and the partial reply", page)
+	}
+}
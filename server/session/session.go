@@ -0,0 +1,95 @@
+// Package session issues the signed anonymous tokens that identify chat
+// visitors, so the user ID a request acts as is vouched for by the server
+// instead of taken from the client.
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"go-ai/config"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CookieName is the cookie carrying a visitor's session token.
+const CookieName = "chat_session"
+
+// cookieMaxAge is how long a visitor keeps their session, and with it their history.
+const cookieMaxAge = 365 * 24 * time.Hour
+
+type contextKey struct{}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// TOKENS
+// ─────────────────────────────────────────────────────────────────────────────
+
+// NewUserID returns a random user ID for a new visitor.
+func NewUserID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Sign returns the token for a user of a tenant: the user ID and its
+// HMAC-SHA256 under SESSION_SECRET. The tenant is signed too, so a token
+// issued by one portfolio isn't accepted by another.
+func Sign(tenantID, userID string) string {
+	return userID + "." + base64.RawURLEncoding.EncodeToString(mac(tenantID, userID))
+}
+
+// Verify returns the user ID of a token signed for the tenant.
+func Verify(tenantID, token string) (string, bool) {
+	userID, sig, ok := strings.Cut(token, ".")
+	if !ok || userID == "" {
+		return "", false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(tenantID, userID)) {
+		return "", false
+	}
+	return userID, true
+}
+
+// Cookie returns the cookie carrying token. Over HTTPS it is sent on
+// cross-site requests too, since the frontend usually lives on another origin.
+func Cookie(token string, secure bool) *http.Cookie {
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	}
+}
+
+func mac(tenantID, userID string) []byte {
+	h := hmac.New(sha256.New, []byte(config.GetSessionSecret()))
+	h.Write([]byte(tenantID + "\x00" + userID))
+	return h.Sum(nil)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CONTEXT
+// ─────────────────────────────────────────────────────────────────────────────
+
+// NewContext returns a copy of ctx acting as the visitor with userID.
+func NewContext(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserID returns the visitor ctx acts as, if its request had a session.
+func UserID(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(contextKey{}).(string)
+	return userID, ok
+}
@@ -0,0 +1,210 @@
+package tenant
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"go-ai/config"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// TYPES
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Tenant is one portfolio served by this backend. Requests are matched to a
+// tenant by API key or Host header, and everything they touch is scoped to it.
+type Tenant struct {
+	ID      string   `json:"id"`
+	Hosts   []string `json:"hosts"`
+	APIKeys []string `json:"apiKeys,omitempty"`
+	// AdminKeys are bearer tokens for the /admin API; none disables it
+	AdminKeys []string `json:"adminKeys,omitempty"`
+
+	// PersonID selects the tenant's Person node; empty uses the only one
+	PersonID string `json:"personId,omitempty"`
+	// Neo4jDatabase holds the tenant's graph; empty uses the server default.
+	// Queries and syncs are only scoped by database, so no two tenants may
+	// share one.
+	Neo4jDatabase string `json:"neo4jDatabase,omitempty"`
+
+	FrontendOrigins        []string `json:"frontendOrigins"`
+	ChatCollection         string   `json:"chatCollection,omitempty"`
+	SummaryCollection      string   `json:"summaryCollection,omitempty"`
+	ConversationCollection string   `json:"conversationCollection,omitempty"`
+
+	PersonaTemplateFile string `json:"personaTemplateFile,omitempty"`
+	PersonaRulesFile    string `json:"personaRulesFile,omitempty"`
+
+	// Models overrides the provider and model per role (planner, answerer, embedder)
+	Models map[string]ModelSetting `json:"models,omitempty"`
+}
+
+// ModelSetting overrides the provider kind and/or model for one role.
+type ModelSetting struct {
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+type contextKey struct{}
+
+var tenants []*Tenant
+
+// ─────────────────────────────────────────────────────────────────────────────
+// LOADING
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Load reads the tenants from TENANTS_FILE, or builds a single default tenant
+// from the environment when it is unset.
+func Load() error {
+	path := config.GetTenantsFile()
+	if path == "" {
+		tenants = []*Tenant{defaultTenant()}
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read tenants file: %w", err)
+	}
+	var loaded []*Tenant
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse tenants file: %w", err)
+	}
+	if len(loaded) == 0 {
+		return fmt.Errorf("tenants file %s defines no tenants", path)
+	}
+
+	seen := map[string]bool{}
+	databases := map[string]string{}
+	for _, t := range loaded {
+		if t.ID == "" {
+			return fmt.Errorf("tenant without an id in %s", path)
+		}
+		if seen[t.ID] {
+			return fmt.Errorf("duplicate tenant id %q in %s", t.ID, path)
+		}
+		seen[t.ID] = true
+		if len(t.Hosts) == 0 && len(t.APIKeys) == 0 {
+			return fmt.Errorf("tenant %q needs at least one host or API key", t.ID)
+		}
+		database := graphDatabase(t.Neo4jDatabase)
+		if other, ok := databases[database]; ok {
+			return fmt.Errorf("tenants %q and %q share Neo4j database %q; give each its own neo4jDatabase", other, t.ID, database)
+		}
+		databases[database] = t.ID
+		if t.ChatCollection == "" {
+			t.ChatCollection = config.GetMongoCollection() + "_" + t.ID
+		}
+		if t.SummaryCollection == "" {
+			t.SummaryCollection = t.ChatCollection + "_summaries"
+		}
+		if t.ConversationCollection == "" {
+			t.ConversationCollection = t.ChatCollection + "_conversations"
+		}
+	}
+	tenants = loaded
+	return nil
+}
+
+// graphDatabase normalises a Neo4j database name for comparison. Names are
+// case-insensitive and an empty one is the server default, "neo4j" unless the
+// server is configured otherwise.
+func graphDatabase(name string) string {
+	if name == "" {
+		return "neo4j"
+	}
+	return strings.ToLower(name)
+}
+
+// defaultTenant serves the single portfolio configured through the environment.
+func defaultTenant() *Tenant {
+	return &Tenant{
+		ID:                     "default",
+		Hosts:                  config.GetAllowedHosts(),
+		AdminKeys:              config.GetAdminAPIKeys(),
+		FrontendOrigins:        []string{config.GetFrontendOrigin()},
+		ChatCollection:         config.GetMongoCollection(),
+		SummaryCollection:      config.GetMongoSummaryCollection(),
+		ConversationCollection: config.GetMongoConversationCollection(),
+		PersonaTemplateFile:    config.GetPersonaTemplateFile(),
+		PersonaRulesFile:       config.GetPersonaRulesFile(),
+	}
+}
+
+// All returns every configured tenant.
+func All() []*Tenant {
+	return tenants
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// RESOLUTION
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Resolve matches a request to a tenant by its X-API-Key header, then by Host.
+func Resolve(r *http.Request) (*Tenant, bool) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		for _, t := range tenants {
+			if slices.Contains(t.APIKeys, key) {
+				return t, true
+			}
+		}
+		return nil, false
+	}
+
+	host := strings.ToLower(r.Host)
+	for _, t := range tenants {
+		for _, h := range t.Hosts {
+			if strings.ToLower(h) == host {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// AllowsOrigin reports whether the tenant's frontend may call the API from origin.
+func (t *Tenant) AllowsOrigin(origin string) bool {
+	return slices.Contains(t.FrontendOrigins, origin)
+}
+
+// AllowsAdminKey reports whether key is one of the tenant's admin keys.
+func (t *Tenant) AllowsAdminKey(key string) bool {
+	for _, k := range t.AdminKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Model returns the tenant's provider and model override for a role, if any.
+func (t *Tenant) Model(role string) (ModelSetting, bool) {
+	m, ok := t.Models[role]
+	return m, ok
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CONTEXT
+// ─────────────────────────────────────────────────────────────────────────────
+
+// NewContext returns a copy of ctx scoped to the tenant.
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant ctx is scoped to. Work started outside a
+// request (startup jobs, CLI commands) without one runs as the first tenant.
+func FromContext(ctx context.Context) *Tenant {
+	if t, ok := ctx.Value(contextKey{}).(*Tenant); ok {
+		return t
+	}
+	if len(tenants) == 0 {
+		return defaultTenant()
+	}
+	return tenants[0]
+}